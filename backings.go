@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"github.com/xfreshx/lifland/rounding"
+	"github.com/xfreshx/lifland/types"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// policyOf returns the rounding policy of a tournament. Tournaments
+// announced before there were policies use the default one.
+func policyOf(t *types.Tournament) rounding.Policy {
+	policy, err := rounding.Parse(t.Rounding)
+	if err != nil {
+		return rounding.Default
+	}
+
+	return policy
+}
+
+// parseBackings reads the backers of a join request. Each backerId may be
+// matched by a backerAmount in points or by a backerPercent of the deposit,
+// given in the same order. Without either the deposit is split equally
+// between the backers and the player. Splits follow the rounding policy, the
+// player being the first share. Backings come back ordered by backer id.
+func parseBackings(params url.Values, playerId string, deposit uint64, policy rounding.Policy) ([]*types.Backing, error) {
+	backers := params["backerId"]
+	amounts := params["backerAmount"]
+	percents := params["backerPercent"]
+
+	if len(amounts) > 0 && len(percents) > 0 {
+		return nil, errors.New("either backerAmount or backerPercent must be given, not both")
+	}
+	if len(amounts) > 0 && len(amounts) != len(backers) || len(percents) > 0 && len(percents) != len(backers) {
+		return nil, errors.New("every backerId needs its own contribution")
+	}
+
+	bb := make([]*types.Backing, 0, len(backers))
+	seen := make(map[string]bool)
+
+	// weights[0] is the player, the rest are the backers in the given order
+	weights := []uint64{0}
+	var total uint64
+
+	for i, backerId := range backers {
+		if backerId == "" || backerId == playerId {
+			return nil, errors.New("invalid backerId given")
+		}
+		if seen[backerId] {
+			return nil, errors.New("backer is given more than once")
+		}
+		seen[backerId] = true
+
+		b := &types.Backing{PlayerId: playerId, BackerId: backerId}
+
+		switch {
+		case len(amounts) > 0:
+			amount, err := strconv.ParseUint(amounts[i], 10, 64)
+			if err != nil {
+				return nil, errors.New("invalid backerAmount given")
+			}
+			// adding up past the deposit also catches an overflowing total
+			if amount > deposit-total {
+				return nil, errors.New("backers contribute more than the deposit")
+			}
+			b.Amount = amount
+			total += amount
+		case len(percents) > 0:
+			percent, err := strconv.ParseFloat(percents[i], 64)
+			if err != nil || percent < 0 || percent > 100 {
+				return nil, errors.New("invalid backerPercent given")
+			}
+			// basis points keep two decimals of a percent exact
+			weights = append(weights, uint64(math.Round(percent*100)))
+			total += weights[i+1]
+			if total > 10000 {
+				return nil, errors.New("backers contribute more than the deposit")
+			}
+		default:
+			weights = append(weights, 1)
+		}
+
+		bb = append(bb, b)
+	}
+
+	switch {
+	case len(percents) > 0:
+		weights[0] = 10000 - total
+	case len(amounts) == 0:
+		weights[0] = 1
+	}
+
+	if len(amounts) == 0 && len(bb) > 0 {
+		shares := policy.Split(deposit, weights)
+		for i, b := range bb {
+			b.Amount = shares[i+1]
+		}
+	}
+
+	for _, b := range bb {
+		if b.Amount == 0 {
+			return nil, errors.New("backer contribution must be positive")
+		}
+	}
+
+	sort.Slice(bb, func(i, j int) bool { return bb[i].BackerId < bb[j].BackerId })
+
+	return bb, nil
+}
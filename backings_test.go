@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/xfreshx/lifland/rounding"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseBackings(t *testing.T) {
+	tests := []struct {
+		query string
+		want  map[string]uint64
+	}{
+		{"", map[string]uint64{}},
+		// the remainder of an equal or percent split goes to the player
+		{"backerId=b&backerId=c", map[string]uint64{"b": 33, "c": 33}},
+		{"backerId=c&backerAmount=30&backerId=b&backerAmount=70", map[string]uint64{"b": 70, "c": 30}},
+		{"backerId=b&backerPercent=25&backerId=c&backerPercent=12.5", map[string]uint64{"b": 25, "c": 12}},
+		{"backerId=b&backerAmount=101", nil},
+		{"backerId=b&backerAmount=60&backerId=c&backerAmount=41", nil},
+		// a total past the largest amount must not wrap around below the deposit
+		{"backerId=b&backerAmount=18446744073709551615&backerId=c&backerAmount=2", nil},
+		{"backerId=b&backerAmount=2&backerId=c&backerAmount=18446744073709551615", nil},
+		{"backerId=b&backerPercent=60&backerId=c&backerPercent=40.01", nil},
+		{"backerId=b&backerAmount=0", nil},
+		{"backerId=b&backerAmount=10&backerPercent=10", nil},
+		{"backerId=b&backerId=c&backerAmount=10", nil},
+		{"backerId=b&backerId=b", nil},
+		{"backerId=a", nil},
+	}
+
+	for _, tt := range tests {
+		params, _ := url.ParseQuery(tt.query)
+		bb, err := parseBackings(params, "a", 100, rounding.RemainderToPlayer)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("parseBackings(%q) succeeded, want an error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBackings(%q) error = %v", tt.query, err)
+			continue
+		}
+
+		got := make(map[string]uint64)
+		for _, b := range bb {
+			got[b.BackerId] = b.Amount
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBackings(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
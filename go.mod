@@ -1,17 +1,23 @@
 module github.com/xfreshx/lifland
 
+go 1.27.1
+
 require (
-	github.com/AndreasBriese/bbloom v0.0.0-20190306092124-e2d15f34fcf9 // indirect
 	github.com/dgraph-io/badger v2.0.0-rc.2+incompatible
+	github.com/gorilla/mux v1.7.1
+	github.com/lib/pq v1.1.0
+	github.com/rubenv/sql-migrate v0.0.0-20190327083759-54bad0a9b051
+)
+
+require (
+	github.com/AndreasBriese/bbloom v0.0.0-20190306092124-e2d15f34fcf9 // indirect
 	github.com/dgryski/go-farm v0.0.0-20190416075124-e1214b5e05dc // indirect
 	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/golang/protobuf v1.3.1 // indirect
-	github.com/gorilla/mux v1.7.1
-	github.com/lib/pq v1.1.0
 	github.com/pkg/errors v0.8.1 // indirect
-	github.com/rubenv/sql-migrate v0.0.0-20190327083759-54bad0a9b051
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2 // indirect
 	golang.org/x/net v0.0.0-20190420063019-afa5a82059c6 // indirect
 	golang.org/x/sys v0.0.0-20190419153524-e8e3143a4f4a // indirect
+	golang.org/x/text v0.3.0 // indirect
 	gopkg.in/gorp.v1 v1.7.2 // indirect
-
 )
@@ -2,19 +2,57 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/lifecycle"
+	"github.com/xfreshx/lifland/payouts"
+	"github.com/xfreshx/lifland/rounding"
 	"github.com/xfreshx/lifland/storage"
 	"github.com/xfreshx/lifland/types"
 	"github.com/xfreshx/lifland/utils"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-func RootHandler(w http.ResponseWriter, r *http.Request) {
+// Config holds service-wide settings of the handlers.
+type Config struct {
+	// Rounding is the rounding policy of tournaments announced without one.
+	Rounding rounding.Policy
+	// ImplicitPlayers lets /fund create the players it does not know, as it
+	// did before players registered.
+	ImplicitPlayers bool
+	// TransferMax is the most points a transfer may move, TransferDailyMax
+	// the most a player may send within a day. 0 leaves them unlimited.
+	TransferMax      uint64
+	TransferDailyMax uint64
+	// TransferAccept holds transfers until their recipient accepts them.
+	TransferAccept bool
+}
+
+// Handlers serves the HTTP API on top of a storage backend.
+type Handlers struct {
+	store storage.Store
+	cfg   Config
+	// now is the clock holds and joins are checked against.
+	now func() time.Time
+}
+
+func NewHandlers(store storage.Store, cfg Config) *Handlers {
+	if cfg.Rounding == "" {
+		cfg.Rounding = rounding.Default
+	}
+
+	return &Handlers{store: store, cfg: cfg, now: time.Now}
+}
+
+func (h *Handlers) RootHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(`Social tournament service. Please register players and start the tournament.`))
 }
 
-func TakeHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) TakeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -36,38 +74,58 @@ func TakeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commit := false
-	err = storage.GetConn().BeginTransaction()
+	tx, err := h.store.Begin()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
-	defer storage.GetConn().FinalizeTransaction(&commit)
+	defer tx.Rollback()
 
-	p, err := storage.GetConn().GetPlayersForUpdate([]string{playerId})
+	p, err := tx.GetPlayersForUpdate([]string{playerId})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	err = takePlayer(p[0], points)
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if err = restricted(p[0], time.Now()); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	err = ledger.Transfer(tx, ledger.Player(p[0]), ledger.Cashier, points, ledger.ReasonTake, utils.GetRequestId(params))
+	if err == ledger.ErrInsufficientPoints {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has insufficient points")
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
+		return
 	}
 
-	if err = storage.GetConn().UpdatePlayer(p[0]); err != nil {
+	if err = tx.UpdatePlayer(p[0]); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	commit = true
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
 }
 
-func FundHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) FundHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -89,20 +147,66 @@ func FundHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p := &types.Player{
-		Id:      playerId,
-		Points:  points,
-		Backers: make(map[string]interface{}),
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
 	}
+	defer tx.Rollback()
 
-	if err = storage.GetConn().SetPlayer(p); err != nil {
+	if h.cfg.ImplicitPlayers {
+		// create the player if needed, the points themselves come through the ledger
+		p := &types.Player{
+			Id: playerId,
+		}
+
+		if err = tx.SetPlayer(p); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	pp, err := tx.GetPlayersForUpdate([]string{playerId})
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
+
+	if len(pp) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if err = restricted(pp[0], time.Now()); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	err = ledger.Transfer(tx, ledger.Cashier, ledger.Player(pp[0]), points, ledger.ReasonFund, utils.GetRequestId(params))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.UpdatePlayer(pp[0]); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
 }
 
-func AnnounceTournamentHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) AnnounceTournamentHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -124,19 +228,185 @@ func AnnounceTournamentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	t := &types.Tournament{
-		Id:      tournamentId,
-		Deposit: deposit,
-		Players: make(map[string]interface{}),
+	policy := h.cfg.Rounding
+	if params.Get("rounding") != "" {
+		if policy, err = rounding.Parse(params.Get("rounding")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid rounding given")
+			return
+		}
+	}
+
+	if params.Get("rake") != "" && params.Get("rakePercent") != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("either rake or rakePercent must be given, not both")
+		return
+	}
+
+	var rake, rakeBasisPoints uint64
+	if params.Get("rake") != "" {
+		if rake, err = utils.GetUintURLParam(params, "rake"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid rake given")
+			return
+		}
+	}
+
+	if params.Get("rakePercent") != "" {
+		percent, err := strconv.ParseFloat(params.Get("rakePercent"), 64)
+		if err != nil || !(percent >= 0 && percent <= 100) {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid rakePercent given")
+			return
+		}
+		rakeBasisPoints = uint64(math.Round(percent * 100))
+	}
+
+	var guarantee uint64
+	if params.Get("guarantee") != "" {
+		if guarantee, err = utils.GetUintURLParam(params, "guarantee"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid guarantee given")
+			return
+		}
 	}
 
-	if err = storage.GetConn().SetTournament(t); err != nil {
+	var maxEntrants, minEntrants uint64
+	var opensAt, closesAt *time.Time
+	var late time.Duration
+
+	if params.Get("maxEntrants") != "" {
+		if maxEntrants, err = utils.GetUintURLParam(params, "maxEntrants"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid maxEntrants given")
+			return
+		}
+	}
+
+	if params.Get("minEntrants") != "" {
+		minEntrants, err = utils.GetUintURLParam(params, "minEntrants")
+		if err != nil || maxEntrants > 0 && minEntrants > maxEntrants {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid minEntrants given")
+			return
+		}
+	}
+
+	if params.Get("registrationOpensAt") != "" {
+		at, err := utils.GetTimeURLParam(params, "registrationOpensAt")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid registrationOpensAt given")
+			return
+		}
+		opensAt = &at
+	}
+
+	if params.Get("registrationClosesAt") != "" {
+		at, err := utils.GetTimeURLParam(params, "registrationClosesAt")
+		if err != nil || opensAt != nil && !at.After(*opensAt) {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid registrationClosesAt given")
+			return
+		}
+		closesAt = &at
+	}
+
+	if params.Get("lateRegistration") != "" {
+		late, err = time.ParseDuration(params.Get("lateRegistration"))
+		if err != nil || late < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid lateRegistration given")
+			return
+		}
+	}
+
+	var reentries, rebuys uint64
+	if params.Get("reentries") != "" {
+		if reentries, err = utils.GetUintURLParam(params, "reentries"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid reentries given")
+			return
+		}
+	}
+
+	if params.Get("rebuys") != "" {
+		if rebuys, err = utils.GetUintURLParam(params, "rebuys"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid rebuys given")
+			return
+		}
+	}
+
+	var payout payouts.Structure
+	if params.Get("payout") != "" {
+		if payout, err = payouts.Parse(params.Get("payout")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid payout given")
+			return
+		}
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		t = &types.Tournament{Id: tournamentId, State: lifecycle.Announced}
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// settings may only change until the registration opens
+	if err = lifecycle.Apply(t, lifecycle.Announce); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament can not be announced again in state " + t.State)
+		return
+	}
+
+	t.Deposit = deposit
+	t.Rounding = string(policy)
+	t.Payout = string(payout)
+	t.Rake = rake
+	t.Guarantee = guarantee
+	t.MaxEntrants = int(maxEntrants)
+	t.MinEntrants = int(minEntrants)
+	t.RegistrationOpensAt = opensAt
+	t.RegistrationClosesAt = closesAt
+	t.LateRegistration = late
+	t.Reentries = int(reentries)
+	t.Rebuys = int(rebuys)
+	t.RakeBasisPoints = rakeBasisPoints
+
+	// the registration opens right away unless asked otherwise
+	if params.Get("openRegistration") != "false" {
+		if err = lifecycle.Apply(t, lifecycle.OpenRegistration); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	if err = tx.SetTournament(t); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 	}
 }
 
-func JoinTournamentHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) JoinTournamentHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -158,66 +428,246 @@ func JoinTournamentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commit := false
-	err = storage.GetConn().BeginTransaction()
+	tx, err := h.store.Begin()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
-	defer storage.GetConn().FinalizeTransaction(&commit)
+	defer tx.Rollback()
 
-	t, err := storage.GetConn().GetTournamentForUpdate(tournamentId)
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	p, err := storage.GetConn().GetPlayersForUpdate([]string{playerId})
+	// the player is told why, a full field or a closed registration is
+	// not a mistake of theirs
+	if err = joinable(t, playerId, h.now()); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println(err.Error())
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	// the player may join with the shares sold of an offer instead of naming backers
+	var offer *types.Offer
+	if offerId := params.Get("offerId"); offerId != "" {
+		if len(params["backerId"]) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("either offerId or backerId may be given, not both")
+			return
+		}
+
+		offer, err = tx.GetOfferForUpdate(offerId)
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such offer")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	p, err := tx.GetPlayersForUpdate([]string{playerId})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	if p[0].Points < t.Deposit {
-		backers, ok := params["backerId"]
-		if !ok {
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if err = restricted(p[0], h.now()); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	// a player already in enters again only when asking for it explicitly
+	entries := t.PlayerEntries(p[0].Id)
+	if len(entries) > 0 && params.Get("reentry") != "true" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has already joined the tournament")
+		return
+	}
+	if len(entries) > t.Reentries {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("player has no re-entries left")
+		return
+	}
+
+	var backings []*types.Backing
+	backerHolds := make(map[string]string)
+
+	if offer != nil {
+		if backings, err = takeUp(offer, t, p[0].Id, h.now()); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			log.Println(err.Error())
+			return
+		}
+
+		// purchases are paid from their holds, the markup on top
+		for _, purchase := range offer.Purchases {
+			backerHolds[purchase.BackerId] = purchase.HoldId
+		}
+	} else if backings, err = parseBackings(params, p[0].Id, t.Deposit, policyOf(t)); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println(err.Error())
+		return
+	}
+
+	// backings split from the deposit by percent or equally record the rounding
+	// policy on their ledger entries, amounts given as they are record none
+	var split rounding.Policy
+	if offer == nil && len(params["backerAmount"]) == 0 {
+		split = policyOf(t)
+	}
+
+	// backers may pay from holds, given by backerHoldId in the order of backerId
+	if holdIds := params["backerHoldId"]; len(holdIds) > 0 {
+		if len(holdIds) != len(params["backerId"]) {
 			w.WriteHeader(http.StatusBadRequest)
-			log.Println("player has insufficient score and no backers provided")
+			log.Println("every backerId needs its own backerHoldId")
 			return
 		}
 
-		// share deposit among all backers + a player himself
-		pointsPerBacker := t.Deposit / uint64(len(backers)+1)
+		for i, backerId := range params["backerId"] {
+			backerHolds[backerId] = holdIds[i]
+		}
+	}
+
+	e := &types.Entry{
+		TournamentId: t.Id,
+		PlayerId:     p[0].Id,
+		Number:       len(entries) + 1,
+		Contribution: t.Deposit,
+		Rake:         t.Fee(),
+		Backings:     backings,
+	}
+	for _, b := range backings {
+		if b.Amount > e.Contribution {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("backers contribute more than the deposit")
+			return
+		}
+
+		b.TournamentId = t.Id
+		b.Entry = e.Number
+		e.Contribution -= b.Amount
+	}
+
+	// the entry goes first, backings refer to it
+	if err = tx.AddTournamentEntry(e); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(backings) > 0 {
+		var backersId []string
+		for _, b := range backings {
+			backersId = append(backersId, b.BackerId)
+		}
 
-		backerPlayers, err := storage.GetConn().GetPlayersForUpdate(backers)
+		backerPlayers, err := tx.GetPlayersForUpdate(backersId)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Println(err.Error())
 			return
 		}
 
+		// the store orders players its own way, backers are looked up by id
+		backers := make(map[string]*types.Player)
 		for _, b := range backerPlayers {
-			err = takePlayer(b, pointsPerBacker)
+			backers[b.Id] = b
+		}
+
+		for _, backing := range backings {
+			b, ok := backers[backing.BackerId]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				log.Println("no such backer " + backing.BackerId)
+				return
+			}
+
+			if err = restricted(b, h.now()); err != nil {
+				w.WriteHeader(http.StatusForbidden)
+				log.Println(err.Error())
+				return
+			}
+		}
+
+		for _, backing := range backings {
+			b := backers[backing.BackerId]
+
+			if holdId := backerHolds[b.Id]; holdId != "" {
+				hold, err := tx.GetHoldForUpdate(holdId)
+				if err == storage.ErrNotFound {
+					w.WriteHeader(http.StatusNotFound)
+					log.Println("no such hold")
+					return
+				}
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					log.Println(err.Error())
+					return
+				}
+
+				if err = usable(hold, b.Id, backing.Amount+backing.Premium, h.now()); err != nil {
+					w.WriteHeader(http.StatusConflict)
+					log.Println(err.Error())
+					return
+				}
+
+				if err = capture(tx, b, hold, backing.Amount+backing.Premium, h.now()); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					log.Println(err.Error())
+					return
+				}
+			}
+
+			err = ledger.Share(tx, ledger.Player(b), ledger.Player(p[0]), backing.Amount, ledger.ReasonBacking, t.Id, split)
+			if err == ledger.ErrInsufficientPoints {
+				w.WriteHeader(http.StatusBadRequest)
+				log.Println("backer has insufficient points")
+				return
+			}
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				log.Println(err.Error())
 				return
 			}
 
-			p[0].Points += pointsPerBacker
-			p[0].Backers[b.Id] = true
-
-			err = storage.GetConn().UpdatePlayer(p[0])
+			// the markup is escrowed by the tournament until its result
+			err = ledger.Transfer(tx, ledger.Player(b), ledger.Tournament(t.Id), backing.Premium, ledger.ReasonMarkup, t.Id)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				log.Println(err.Error())
 				return
 			}
 
-			err = storage.GetConn().UpdatePlayer(b)
+			if err = tx.AddBacking(backing); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				log.Println(err.Error())
+				return
+			}
+
+			err = tx.UpdatePlayer(b)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				log.Println(err.Error())
@@ -226,31 +676,82 @@ func JoinTournamentHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = takePlayer(p[0], t.Deposit)
+	// the player may pay its contribution and the rake from a hold
+	if holdId := params.Get("holdId"); holdId != "" {
+		hold, err := tx.GetHoldForUpdate(holdId)
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such hold")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = usable(hold, p[0].Id, e.Contribution+e.Rake, h.now()); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = capture(tx, p[0], hold, e.Contribution+e.Rake, h.now()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	err = ledger.Transfer(tx, ledger.Player(p[0]), ledger.Tournament(t.Id), t.Deposit, ledger.ReasonBuyIn, t.Id)
+	if err == ledger.ErrInsufficientPoints {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has insufficient points")
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	err = storage.GetConn().UpdatePlayer(p[0])
+	// the rake is paid by the player on top of the deposit
+	err = ledger.Transfer(tx, ledger.Player(p[0]), ledger.House, e.Rake, ledger.ReasonRake, t.Id)
+	if err == ledger.ErrInsufficientPoints {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has insufficient points")
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	t.Players[p[0].Id] = true
-	if err = storage.GetConn().UpdateTournament(t); err != nil {
+	if offer != nil {
+		offer.Status, offer.Entry = types.OfferTaken, e.Number
+
+		if err = tx.UpdateOffer(offer); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	err = tx.UpdatePlayer(p[0])
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	commit = true
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
 }
 
-func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -258,11 +759,13 @@ func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 
 	decoder := json.NewDecoder(r.Body)
 	tournamentResult := struct {
-		TournamentId string `json:"tournamentId"`
-		Winners      []struct {
-			PlayerId string `json:"playerId"`
-			Prize    uint64 `json:"prize"`
-		} `json:"winners"`
+		TournamentId string   `json:"tournamentId"`
+		Winners      []winner `json:"winners"`
+		// Order is the finishing order, best first, to compute the
+		// winners from with the payout structure of the tournament.
+		Order []string `json:"order"`
+		// Placements rank the players and may tie them.
+		Placements []placement `json:"placements"`
 	}{}
 
 	err := decoder.Decode(&tournamentResult)
@@ -278,16 +781,121 @@ func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commit := false
-	err = storage.GetConn().BeginTransaction()
+	given := 0
+	for _, n := range []int{len(tournamentResult.Winners), len(tournamentResult.Order), len(tournamentResult.Placements)} {
+		if n > 0 {
+			given++
+		}
+	}
+	if given > 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("only one of winners, order or placements may be given")
+		return
+	}
+
+	if len(tournamentResult.Order) > 0 {
+		tournamentResult.Placements = orderPlacements(tournamentResult.Order)
+	}
+
+	tx, err := h.store.Begin()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
-	defer storage.GetConn().FinalizeTransaction(&commit)
+	defer tx.Rollback()
 
-	t, err := storage.GetConn().GetTournamentForUpdate(tournamentResult.TournamentId)
+	t, err := tx.GetTournamentForUpdate(tournamentResult.TournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// a tournament resulted right from its registration is closed and started on the way
+	state := t.State
+	for _, op := range []lifecycle.Operation{lifecycle.CloseRegistration, lifecycle.Start} {
+		if !lifecycle.Allows(t, op) {
+			continue
+		}
+
+		if err = transition(tx, t, op); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	// the cancellation of a tournament short of its minimum field stands
+	if state != lifecycle.Cancelled && t.State == lifecycle.Cancelled {
+		if err = tx.UpdateTournament(t); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament did not reach its minimum field and was cancelled")
+		_, _ = w.Write([]byte("tournament did not reach its minimum field and was cancelled"))
+		return
+	}
+
+	if err = lifecycle.Apply(t, lifecycle.Result); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament can not be resulted in state " + t.State)
+		return
+	}
+
+	// prizes ranked from placements are split by the rounding policy and
+	// record it on their ledger entries, prizes given as they are record none
+	var split rounding.Policy
+	if len(tournamentResult.Placements) > 0 {
+		tournamentResult.Winners, err = rankPrizes(t, tournamentResult.Placements)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println(err.Error())
+			return
+		}
+		split = policyOf(t)
+	}
+
+	var prizes uint64
+	for _, winner := range tournamentResult.Winners {
+		if prizes+winner.Prize < prizes {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("prizes are too large")
+			return
+		}
+		prizes += winner.Prize
+	}
+
+	// prizes pay out the whole pool, nothing is left in the tournament, and
+	// may only exceed it by the overlay the house agreed to
+	pool := t.Pool()
+	if prizes < pool {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("prizes do not pay out the prize pool")
+		return
+	}
+	if prizes > pool+t.Overlay {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("prizes exceed the prize pool")
+		return
+	}
+
+	// the house covers the prizes the entries did not pay for
+	err = ledger.Transfer(tx, ledger.House, ledger.Tournament(t.Id), t.OverlayUsed(prizes), ledger.ReasonOverlay, t.Id)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
@@ -296,37 +904,57 @@ func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 
 	for _, winner := range tournamentResult.Winners {
 
-		if _, found := t.Players[winner.PlayerId]; !found {
+		e, err := entryOf(t, winner.PlayerId, winner.Entry)
+		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			log.Println("no such player registered in the tournament")
+			log.Println(err.Error())
+			return
+		}
+
+		p, err := tx.GetPlayersForUpdate([]string{winner.PlayerId})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
 			return
 		}
 
-		p, err := storage.GetConn().GetPlayersForUpdate([]string{winner.PlayerId})
+		if len(p) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such player")
+			return
+		}
+
+		// a frozen player is paid in escrow, its backers are paid from there
+		account := payee(p[0])
+
+		err = ledger.Share(tx, ledger.Tournament(t.Id), account, winner.Prize, ledger.ReasonPrize, t.Id, split)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Println(err.Error())
 			return
 		}
 
-		p[0].Points += winner.Prize
-		err = storage.GetConn().UpdatePlayer(p[0])
+		err = tx.UpdatePlayer(p[0])
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Println(err.Error())
 			return
 		}
 
-		if len(p[0].Backers) > 0 {
-			// pay back in the same ratio
-			pointsPerBacker := winner.Prize / uint64(len(p[0].Backers)+1)
+		if len(e.Backings) > 0 {
+			// pay the backers of this entry by their stakes, the player keeps the rest
+			policy := policyOf(t)
+			shares := policy.Split(winner.Prize, e.Stakes())
 
+			payouts := make(map[string]uint64)
 			var backersId []string
-			for backerId, _ := range p[0].Backers {
-				backersId = append(backersId, backerId)
+			for i, b := range e.Backings {
+				payouts[b.BackerId] = shares[i+1]
+				b.Payout += shares[i+1]
+				backersId = append(backersId, b.BackerId)
 			}
 
-			backerPlayers, err := storage.GetConn().GetPlayersForUpdate(backersId)
+			backerPlayers, err := tx.GetPlayersForUpdate(backersId)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				log.Println(err.Error())
@@ -335,17 +963,14 @@ func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 
 			for _, b := range backerPlayers {
 
-				err = takePlayer(p[0], pointsPerBacker)
+				err = ledger.Share(tx, account, payee(b), payouts[b.Id], ledger.ReasonPayout, t.Id, policy)
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
 					log.Println(err.Error())
 					return
 				}
 
-				b.Points += pointsPerBacker
-				delete(p[0].Backers, b.Id)
-
-				err = storage.GetConn().UpdatePlayer(b)
+				err = tx.UpdatePlayer(b)
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
 					log.Println(err.Error())
@@ -354,25 +979,46 @@ func ResultTournamentHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		err = storage.GetConn().UpdatePlayer(p[0])
+		err = tx.UpdatePlayer(p[0])
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Println(err.Error())
 			return
 		}
+
+		e.Prize += winner.Prize
+		e.Rank = winner.Rank
+		if err = tx.UpdateTournamentEntry(e); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
 	}
 
-	err = storage.GetConn().DeleteTournament(tournamentResult.TournamentId)
+	if err = releaseMarkups(tx, t); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// the tournament is kept with its entries as the record of the result
+	now := time.Now()
+	t.FinishedAt = &now
+
+	err = tx.UpdateTournament(t)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	commit = true
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
 }
 
-func BalanceHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) BalanceHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
@@ -387,14 +1033,24 @@ func BalanceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p, err := storage.GetConn().GetPlayer(playerId)
+	p, err := h.store.GetPlayer(playerId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 		return
 	}
 
-	j, err := json.Marshal(p)
+	// the balance includes the points held, available leaves them out
+	j, err := json.Marshal(struct {
+		Id        string `json:"id"`
+		Balance   uint64 `json:"balance"`
+		Available uint64 `json:"available"`
+	}{p.Id, p.Points, p.Available()})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
@@ -407,26 +1063,198 @@ func BalanceHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func ResetHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) ResetHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := storage.GetConn().Reset()
+	err := h.store.Reset()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println(err.Error())
 	}
 }
 
-func takePlayer(p *types.Player, points uint64) error {
+// PlayerTransactionsHandler lists points movements of a player, newest first.
+// The type, from and to params filter them, cursor and limit page through them.
+func (h *Handlers) PlayerTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerId := mux.Vars(r)["id"]
+	params := r.URL.Query()
+
+	f := types.LedgerFilter{
+		Account: ledger.PlayerAccount(playerId),
+		Reasons: params["type"],
+		Limit:   50,
+	}
+
+	var err error
+	if params.Get("from") != "" {
+		if f.From, err = utils.GetTimeURLParam(params, "from"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid from given")
+			return
+		}
+	}
+
+	if params.Get("to") != "" {
+		if f.To, err = utils.GetTimeURLParam(params, "to"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid to given")
+			return
+		}
+	}
+
+	if params.Get("cursor") != "" {
+		cursor, err := utils.GetCursorURLParam(params, "cursor")
+		if err == nil {
+			f.Before, err = strconv.ParseUint(cursor, 10, 64)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid cursor given")
+			return
+		}
+	}
+
+	if params.Get("limit") != "" {
+		limit, err := utils.GetUintURLParam(params, "limit")
+		if err != nil || limit == 0 || limit > 500 {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid limit given")
+			return
+		}
+		f.Limit = int(limit)
+	}
+
+	if _, err = h.store.GetPlayer(playerId); err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// one more entry tells whether there is a next page
+	limit := f.Limit
+	f.Limit++
+
+	entries, err := h.store.ListLedgerEntries(f)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
 
-	if p.Points >= points {
-		p.Points -= points
-	} else {
-		return errors.New("not enough points to take")
+	type transaction struct {
+		Id   uint64 `json:"id"`
+		Type string `json:"type"`
+		// Amount is negative when points left the player
+		Amount       int64     `json:"amount"`
+		Counterparty string    `json:"counterparty"`
+		Reference    string    `json:"reference"`
+		Rounding     string    `json:"rounding,omitempty"`
+		CreatedAt    time.Time `json:"createdAt"`
 	}
 
-	return nil
+	resp := struct {
+		Transactions []transaction `json:"transactions"`
+		NextCursor   string        `json:"nextCursor,omitempty"`
+	}{Transactions: []transaction{}}
+
+	for i, e := range entries {
+		if i == limit {
+			resp.NextCursor = utils.NewCursor(strconv.FormatUint(entries[i-1].Id, 10))
+			break
+		}
+
+		t := transaction{
+			Id:           e.Id,
+			Type:         e.Reason,
+			Amount:       int64(e.Amount),
+			Counterparty: e.Debit,
+			Reference:    e.Reference,
+			Rounding:     e.Rounding,
+			CreatedAt:    e.CreatedAt,
+		}
+		if e.Debit == f.Account {
+			t.Amount = -t.Amount
+			t.Counterparty = e.Credit
+		}
+
+		resp.Transactions = append(resp.Transactions, t)
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// AuditHandler compares the stored balance of a player with the one derived
+// from the ledger.
+func (h *Handlers) AuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	p, err := h.store.GetPlayer(playerId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	ledgerBalance, err := h.store.GetAccountBalance(ledger.PlayerAccount(playerId))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	j, err := json.Marshal(struct {
+		Id            string `json:"id"`
+		Balance       uint64 `json:"balance"`
+		LedgerBalance int64  `json:"ledgerBalance"`
+		Consistent    bool   `json:"consistent"`
+	}{p.Id, p.Points, ledgerBalance, int64(p.Points) == ledgerBalance})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
 }
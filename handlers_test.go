@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/xfreshx/lifland/storage"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// testServer serves the handlers on a memory store.
+type testServer struct {
+	t *testing.T
+	h *Handlers
+	*httptest.Server
+}
+
+func newTestServer(t *testing.T, cfg Config) *testServer {
+	h := NewHandlers(storage.NewMemoryStore(), cfg)
+	s := httptest.NewServer(routes(h))
+	t.Cleanup(s.Close)
+
+	return &testServer{t: t, h: h, Server: s}
+}
+
+// send requests path and fails the test unless it answers with status. It
+// returns the body of the response.
+func (s *testServer) send(method, path string, body io.Reader, status int) []byte {
+	s.t.Helper()
+
+	req, err := http.NewRequest(method, s.URL+path, body)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		s.t.Fatalf("%s %s: status %d, want %d", method, path, resp.StatusCode, status)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	return b
+}
+
+func (s *testServer) do(method, path string, body io.Reader, status int) map[string]interface{} {
+	s.t.Helper()
+
+	v := make(map[string]interface{})
+	if b := s.send(method, path, body, status); len(b) > 0 {
+		_ = json.Unmarshal(b, &v)
+	}
+
+	return v
+}
+
+// get requests path and fails the test unless it answers with status.
+func (s *testServer) get(path string, status int) map[string]interface{} {
+	s.t.Helper()
+
+	return s.do(http.MethodGet, path, nil, status)
+}
+
+func (s *testServer) post(path, body string, status int) map[string]interface{} {
+	s.t.Helper()
+
+	return s.do(http.MethodPost, path, strings.NewReader(body), status)
+}
+
+// register registers the players and funds each with points.
+func (s *testServer) register(points string, ids ...string) {
+	s.t.Helper()
+
+	for _, id := range ids {
+		s.post("/players", `{"id":"`+id+`","name":"`+id+`"}`, http.StatusCreated)
+		s.get("/fund?playerId="+id+"&points="+points, http.StatusOK)
+	}
+}
+
+// balances checks the balance and the available points of players, and
+// that their balances agree with the ledger.
+func (s *testServer) balances(want map[string][2]float64) {
+	s.t.Helper()
+
+	for id, w := range want {
+		b := s.get("/balance?playerId="+id, http.StatusOK)
+		if b["balance"] != w[0] || b["available"] != w[1] {
+			s.t.Errorf("player %s has balance %v, available %v, want %v, %v", id, b["balance"], b["available"], w[0], w[1])
+		}
+
+		if a := s.get("/audit?playerId="+id, http.StatusOK); a["consistent"] != true {
+			s.t.Errorf("player %s balance %v does not match the ledger %v", id, a["balance"], a["ledgerBalance"])
+		}
+	}
+}
+
+func deadline() string {
+	return time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+}
+
+// A backer shares in the entry they backed, not in every entry of the player.
+func TestBackingScopedToEntry(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=90", http.StatusOK)
+	s.get("/announceTournament?tournamentId=t2&deposit=100", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=b", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t2&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t2&playerId=c", http.StatusOK)
+	s.balances(map[string][2]float64{"a": {855, 855}, "b": {955, 955}, "c": {810, 810}})
+
+	for _, id := range []string{"t1", "t2"} {
+		s.get("/closeRegistration?tournamentId="+id, http.StatusOK)
+		s.get("/startTournament?tournamentId="+id, http.StatusOK)
+	}
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":180}]}`, http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t2","winners":[{"playerId":"a","prize":200}]}`, http.StatusOK)
+
+	s.balances(map[string][2]float64{"a": {1145, 1145}, "b": {1045, 1045}, "c": {810, 810}})
+}
+
+func TestJoinWithBackerContributions(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c", "d")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=c&backerAmount=30&backerId=x&backerAmount=10", http.StatusNotFound)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=c&backerAmount=70&backerId=b&backerAmount=31", http.StatusBadRequest)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=c&backerAmount=30&backerId=b&backerAmount=10", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=d&backerId=b&backerPercent=25", http.StatusOK)
+	s.balances(map[string][2]float64{"a": {940, 940}, "b": {965, 965}, "c": {970, 970}, "d": {925, 925}})
+
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":120},{"playerId":"d","prize":80}]}`, http.StatusOK)
+
+	// each backer is paid in proportion to what they put in
+	s.balances(map[string][2]float64{"a": {1012, 1012}, "b": {997, 997}, "c": {1006, 1006}, "d": {985, 985}})
+}
+
+func TestResultWithinPool(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=b", http.StatusOK)
+	if pool := s.get("/tournaments/t1/pool", http.StatusOK); pool["collected"] != 200.0 || pool["total"] != 200.0 {
+		t.Errorf("pool is %v", pool)
+	}
+
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":150},{"playerId":"b","prize":51}]}`, http.StatusBadRequest)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":150},{"playerId":"b","prize":50}]}`, http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":200}]}`, http.StatusConflict)
+
+	if pool := s.get("/tournaments/t1/pool", http.StatusOK); pool["prizes"] != 200.0 {
+		t.Errorf("pool is %v, want 200 paid out", pool)
+	}
+	s.balances(map[string][2]float64{"a": {1050, 1050}, "b": {950, 950}})
+}
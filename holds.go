@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"net/http"
+	"time"
+)
+
+// PlaceHoldHandler reserves points of a player for expiresIn, a duration
+// such as 15m. Held points stay in the balance but may not be spent other
+// than by capturing the hold.
+func (h *Handlers) PlaceHoldHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	points, err := utils.GetUintURLParam(params, "points")
+	if err != nil || points == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid points given")
+		return
+	}
+
+	expiresIn, err := time.ParseDuration(params.Get("expiresIn"))
+	if err != nil || expiresIn <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid expiresIn given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	p, err := tx.GetPlayersForUpdate([]string{playerId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	now := h.now()
+	if err = restricted(p[0], now); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	if p[0].Available() < points {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has insufficient points")
+		return
+	}
+
+	hold := &types.Hold{
+		Id:        utils.NewId(),
+		PlayerId:  p[0].Id,
+		Amount:    points,
+		Status:    types.HoldActive,
+		ExpiresAt: now.Add(expiresIn),
+	}
+
+	if err = tx.AddHold(hold); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeHold(w, hold, now)
+}
+
+// CaptureHoldHandler takes points of the hold given by holdId from the
+// player, all of them unless points says otherwise. What is not captured
+// is released.
+func (h *Handlers) CaptureHoldHandler(w http.ResponseWriter, r *http.Request) {
+	h.settleHold(w, r, true)
+}
+
+// ReleaseHoldHandler gives the points of the hold given by holdId back to
+// the player.
+func (h *Handlers) ReleaseHoldHandler(w http.ResponseWriter, r *http.Request) {
+	h.settleHold(w, r, false)
+}
+
+func (h *Handlers) settleHold(w http.ResponseWriter, r *http.Request, take bool) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	holdId, err := utils.GetStringURLParam(params, "holdId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid holdId given")
+		return
+	}
+
+	var points uint64
+	if take && params.Get("points") != "" {
+		if points, err = utils.GetUintURLParam(params, "points"); err != nil || points == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid points given")
+			return
+		}
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	// players are locked before their holds
+	hold, err := tx.GetHold(holdId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such hold")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	p, err := tx.GetPlayersForUpdate([]string{hold.PlayerId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if hold, err = tx.GetHoldForUpdate(holdId); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	now := h.now()
+	if points == 0 && take {
+		points = hold.Amount
+	}
+
+	if take {
+		if err = restricted(p[0], now); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	if err = usable(hold, p[0].Id, points, now); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = capture(tx, p[0], hold, points, now); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if take {
+		err = ledger.Transfer(tx, ledger.Player(p[0]), ledger.Cashier, points, ledger.ReasonTake, hold.Id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.UpdatePlayer(p[0]); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeHold(w, hold, now)
+}
+
+// HoldHandler returns a hold with its status.
+func (h *Handlers) HoldHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	hold, err := h.store.GetHold(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such hold")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeHold(w, hold, h.now())
+}
+
+// usable tells why points of hold may not be captured for playerId at now,
+// nil when they may.
+func usable(hold *types.Hold, playerId string, points uint64, now time.Time) error {
+	if hold.PlayerId != playerId {
+		return errors.New("hold " + hold.Id + " belongs to another player")
+	}
+
+	if status := hold.StatusAt(now); status != types.HoldActive {
+		return errors.New("hold " + hold.Id + " is " + status)
+	}
+
+	if points > hold.Amount {
+		return errors.New("hold " + hold.Id + " does not cover the points")
+	}
+
+	return nil
+}
+
+// capture settles hold by capturing points of it and releasing the rest. The
+// points are no longer held from p, the caller still has to move them.
+func capture(tx storage.Tx, p *types.Player, hold *types.Hold, points uint64, now time.Time) error {
+	hold.Captured = points
+	hold.Status = types.HoldReleased
+	if points > 0 {
+		hold.Status = types.HoldCaptured
+	}
+	hold.SettledAt = &now
+
+	p.Held -= hold.Amount
+
+	return tx.UpdateHold(hold)
+}
+
+func writeHold(w http.ResponseWriter, hold *types.Hold, now time.Time) {
+	hold.Status = hold.StatusAt(now)
+
+	j, err := json.Marshal(hold)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
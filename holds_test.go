@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHolds(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	hold := s.get("/hold?playerId=a&points=300&expiresIn=1h", http.StatusOK)
+	s.get("/hold?playerId=a&points=701&expiresIn=1h", http.StatusBadRequest)
+	s.get("/captureHold?holdId=x", http.StatusNotFound)
+	s.balances(map[string][2]float64{"a": {1000, 700}})
+
+	// held points can not be spent otherwise
+	s.get("/take?playerId=a&points=800", http.StatusBadRequest)
+	s.get("/transfer?playerId=a&recipientId=b&points=800", http.StatusBadRequest)
+
+	s.get("/captureHold?holdId="+hold["id"].(string)+"&points=400", http.StatusConflict)
+	s.get("/captureHold?holdId="+hold["id"].(string)+"&points=200", http.StatusOK)
+	s.get("/releaseHold?holdId="+hold["id"].(string), http.StatusConflict)
+	s.balances(map[string][2]float64{"a": {800, 800}})
+
+	hold = s.get("/hold?playerId=a&points=100&expiresIn=1h", http.StatusOK)
+	s.get("/releaseHold?holdId="+hold["id"].(string), http.StatusOK)
+	s.balances(map[string][2]float64{"a": {800, 800}})
+}
+
+func TestHoldExpires(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a")
+
+	// the hold is placed two hours ago for an hour
+	s.h.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	hold := s.get("/hold?playerId=a&points=100&expiresIn=1h", http.StatusOK)
+	if hold["status"] != "active" {
+		t.Errorf("hold is %v, want active", hold["status"])
+	}
+	s.h.now = time.Now
+
+	s.get("/captureHold?holdId="+hold["id"].(string), http.StatusConflict)
+	if h := s.get("/holds/"+hold["id"].(string), http.StatusOK); h["status"] != "expired" {
+		t.Errorf("hold is %v, want expired", h["status"])
+	}
+	s.balances(map[string][2]float64{"a": {1000, 1000}})
+}
+
+func TestJoinWithBackerHold(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	hold := s.get("/hold?playerId=b&points=50&expiresIn=1h", http.StatusOK)
+	s.balances(map[string][2]float64{"b": {1000, 950}})
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=b&backerAmount=60&backerHoldId="+hold["id"].(string), http.StatusConflict)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=b&backerAmount=40&backerHoldId="+hold["id"].(string), http.StatusOK)
+
+	if h := s.get("/holds/"+hold["id"].(string), http.StatusOK); h["status"] != "captured" || h["captured"] != 40.0 {
+		t.Errorf("hold is %v with %v captured, want captured with 40", h["status"], h["captured"])
+	}
+	s.balances(map[string][2]float64{"a": {940, 940}, "b": {960, 960}})
+}
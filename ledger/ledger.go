@@ -0,0 +1,159 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/xfreshx/lifland/rounding"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+)
+
+// Reasons of ledger entries.
+const (
+	ReasonOpening  = "opening"
+	ReasonFund     = "fund"
+	ReasonTake     = "take"
+	ReasonBuyIn    = "buyin"
+	ReasonRebuy    = "rebuy"
+	ReasonBacking  = "backing"
+	ReasonPrize    = "prize"
+	ReasonPayout   = "payout"
+	ReasonRefund   = "refund"
+	ReasonOverlay  = "overlay"
+	ReasonRake     = "rake"
+	ReasonRelease  = "release"
+	ReasonForfeit  = "forfeit"
+	ReasonTransfer = "transfer"
+	ReasonMarkup   = "markup"
+)
+
+// CashierAccount is where funded points come from and taken points go to.
+const CashierAccount = "cashier"
+
+// HouseAccount collects the rake and pays for what the service adds to
+// prize pools.
+const HouseAccount = "house"
+
+var ErrInsufficientPoints = errors.New("not enough points to take")
+
+// Account is a side of a ledger entry.
+type Account interface {
+	Name() string
+	withdraw(points uint64) error
+	deposit(points uint64)
+}
+
+type player struct {
+	*types.Player
+}
+
+// Player is the account of a player. Transfers change p.Points, the caller
+// still has to save the player. Points held are not withdrawn.
+func Player(p *types.Player) Account {
+	return player{p}
+}
+
+func (a player) Name() string {
+	return PlayerAccount(a.Id)
+}
+
+func (a player) withdraw(points uint64) error {
+	if a.Available() < points {
+		return ErrInsufficientPoints
+	}
+	a.Points -= points
+
+	return nil
+}
+
+func (a player) deposit(points uint64) {
+	a.Points += points
+}
+
+// system is an account of the service itself. It has no stored balance and
+// may go below zero.
+type system string
+
+func (a system) Name() string {
+	return string(a)
+}
+
+func (a system) withdraw(points uint64) error {
+	return nil
+}
+
+func (a system) deposit(points uint64) {}
+
+// Cashier is the system account on the outer side of funds and takes.
+var Cashier Account = system(CashierAccount)
+
+// House is the system account of the service itself.
+var House Account = system(HouseAccount)
+
+// Tournament is the pool account of a tournament: buy-ins are credited to it
+// and prizes are debited from it.
+func Tournament(id string) Account {
+	return system(TournamentAccount(id))
+}
+
+// Escrow holds what a frozen player is paid until its account is reviewed.
+func Escrow(playerId string) Account {
+	return system(EscrowAccount(playerId))
+}
+
+// Pending holds the points of a transfer until its recipient accepts it.
+func Pending(transferId string) Account {
+	return system(TransferAccount(transferId))
+}
+
+func PlayerAccount(id string) string {
+	return "player:" + id
+}
+
+func TournamentAccount(id string) string {
+	return "tournament:" + id
+}
+
+func EscrowAccount(playerId string) string {
+	return "escrow:" + playerId
+}
+
+func TransferAccount(id string) string {
+	return "transfer:" + id
+}
+
+// Transfer moves points between two accounts and records the ledger entry
+// in the same transaction. Zero transfers are not recorded.
+func Transfer(q storage.Queries, debit, credit Account, points uint64, reason, reference string) error {
+	return post(q, debit, credit, &types.LedgerEntry{
+		Amount:    points,
+		Reason:    reason,
+		Reference: reference,
+	})
+}
+
+// Share is a Transfer of an amount computed by splitting with a rounding
+// policy. The policy is recorded on the ledger entry.
+func Share(q storage.Queries, debit, credit Account, points uint64, reason, reference string, policy rounding.Policy) error {
+	return post(q, debit, credit, &types.LedgerEntry{
+		Amount:    points,
+		Reason:    reason,
+		Reference: reference,
+		Rounding:  string(policy),
+	})
+}
+
+func post(q storage.Queries, debit, credit Account, e *types.LedgerEntry) error {
+	if e.Amount == 0 {
+		return nil
+	}
+
+	if err := debit.withdraw(e.Amount); err != nil {
+		return err
+	}
+	credit.deposit(e.Amount)
+
+	e.Debit = debit.Name()
+	e.Credit = credit.Name()
+
+	return q.InsertLedgerEntry(e)
+}
@@ -0,0 +1,98 @@
+package lifecycle
+
+import (
+	"errors"
+	"github.com/xfreshx/lifland/types"
+	"sort"
+)
+
+// States of a tournament.
+const (
+	Announced          = "announced"
+	RegistrationOpen   = "registration_open"
+	RegistrationClosed = "registration_closed"
+	InProgress         = "in_progress"
+	Resulted           = "resulted"
+	Cancelled          = "cancelled"
+)
+
+// Operation is something done to a tournament. Every operation is allowed
+// in some states only and may move the tournament to another state.
+type Operation string
+
+const (
+	Announce          Operation = "announce"
+	OpenRegistration  Operation = "openRegistration"
+	CloseRegistration Operation = "closeRegistration"
+	Join              Operation = "join"
+	// LateJoin is a join after the start, within the late registration.
+	LateJoin Operation = "lateJoin"
+	Start    Operation = "start"
+	Result   Operation = "result"
+	Cancel   Operation = "cancel"
+)
+
+var ErrNotAllowed = errors.New("operation is not allowed in the current state of the tournament")
+
+// operations maps every operation to the states it is allowed in and the
+// state each of them moves to.
+var operations = map[Operation]map[string]string{
+	Announce: {
+		Announced: Announced,
+	},
+	OpenRegistration: {
+		Announced:          RegistrationOpen,
+		RegistrationClosed: RegistrationOpen,
+	},
+	CloseRegistration: {
+		RegistrationOpen: RegistrationClosed,
+	},
+	Join: {
+		RegistrationOpen: RegistrationOpen,
+	},
+	LateJoin: {
+		InProgress: InProgress,
+	},
+	Start: {
+		RegistrationClosed: InProgress,
+	},
+	Result: {
+		InProgress: Resulted,
+	},
+	Cancel: {
+		Announced:          Cancelled,
+		RegistrationOpen:   Cancelled,
+		RegistrationClosed: Cancelled,
+		InProgress:         Cancelled,
+	},
+}
+
+func Allows(t *types.Tournament, op Operation) bool {
+	_, ok := operations[op][t.State]
+	return ok
+}
+
+// Apply performs op on the state of t.
+func Apply(t *types.Tournament, op Operation) error {
+	next, ok := operations[op][t.State]
+	if !ok {
+		return ErrNotAllowed
+	}
+
+	t.State = next
+	return nil
+}
+
+// Allowed lists the operations allowed in the state of t.
+func Allowed(t *types.Tournament) []Operation {
+	ops := []Operation{}
+	for op, from := range operations {
+		if _, ok := from[t.State]; ok {
+			ops = append(ops, op)
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	return ops
+}
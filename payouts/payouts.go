@@ -0,0 +1,128 @@
+package payouts
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Structure divides the prize pool of a tournament between its places. Next
+// to the named structures it may be a list of weights, "weights:50,30,20",
+// or a share of the field paid along a curve, "top-percent:15" or
+// "top-percent:15:1.5". The curve is the exponent the weight of a place
+// falls with, 1 when left out.
+type Structure string
+
+const (
+	WinnerTakesAll Structure = "winner-takes-all"
+	// Top3 pays 50%, 30% and 20% of the pool to the first three places.
+	Top3 Structure = "top-3"
+)
+
+const (
+	weightsPrefix    = "weights:"
+	topPercentPrefix = "top-percent:"
+)
+
+// curveScale is the weight of the first place on a curve.
+const curveScale = 1000000
+
+var ErrUnknownStructure = errors.New("unknown payout structure")
+
+func Parse(s string) (Structure, error) {
+	if _, err := Structure(s).weights(1); err != nil {
+		return "", err
+	}
+
+	return Structure(s), nil
+}
+
+// Weights returns the weights of the paid places, first place first, for a
+// field of the given number of entrants. No more places are paid than there
+// are entrants.
+func (s Structure) Weights(entrants int) []uint64 {
+	weights, err := s.weights(entrants)
+	if err != nil {
+		return nil
+	}
+
+	if len(weights) > entrants {
+		weights = weights[:entrants]
+	}
+
+	return weights
+}
+
+func (s Structure) weights(entrants int) ([]uint64, error) {
+	switch {
+	case s == WinnerTakesAll:
+		return []uint64{1}, nil
+	case s == Top3:
+		return []uint64{50, 30, 20}, nil
+	case strings.HasPrefix(string(s), weightsPrefix):
+		return parseWeights(strings.TrimPrefix(string(s), weightsPrefix))
+	case strings.HasPrefix(string(s), topPercentPrefix):
+		return topPercent(strings.TrimPrefix(string(s), topPercentPrefix), entrants)
+	}
+
+	return nil, ErrUnknownStructure
+}
+
+func parseWeights(s string) ([]uint64, error) {
+	var weights []uint64
+	var total uint64
+
+	for _, f := range strings.Split(s, ",") {
+		w, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, ErrUnknownStructure
+		}
+
+		weights = append(weights, w)
+		total += w
+	}
+
+	if total == 0 {
+		return nil, ErrUnknownStructure
+	}
+
+	return weights, nil
+}
+
+// topPercent pays the best percent of the field, at least one place. The
+// weight of place i is curveScale / i^curve.
+func topPercent(s string, entrants int) ([]uint64, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) > 2 {
+		return nil, ErrUnknownStructure
+	}
+
+	percent, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || !(percent > 0 && percent <= 100) {
+		return nil, ErrUnknownStructure
+	}
+
+	curve := 1.0
+	if len(fields) == 2 {
+		curve, err = strconv.ParseFloat(fields[1], 64)
+		if err != nil || !(curve >= 0 && curve <= 10) {
+			return nil, ErrUnknownStructure
+		}
+	}
+
+	places := int(math.Ceil(float64(entrants) * percent / 100))
+	if places < 1 {
+		places = 1
+	}
+
+	weights := make([]uint64, places)
+	for i := range weights {
+		weights[i] = uint64(math.Round(curveScale / math.Pow(float64(i+1), curve)))
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+	}
+
+	return weights, nil
+}
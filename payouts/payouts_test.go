@@ -0,0 +1,112 @@
+package payouts
+
+import (
+	"github.com/xfreshx/lifland/rounding"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s  string
+		ok bool
+	}{
+		{"winner-takes-all", true},
+		{"top-3", true},
+		{"weights:50,30,20", true},
+		{"weights:3,1", true},
+		{"weights:0,1", true},
+		{"weights:7", true},
+		{"top-percent:15", true},
+		{"top-percent:15:1.5", true},
+		{"top-percent:100", true},
+		{"top-percent:0.5:0", true},
+		{"", false},
+		{"top-4", false},
+		{"weights:", false},
+		{"weights:0,0", false},
+		{"weights:50,,20", false},
+		{"weights:-1,2", false},
+		{"weights:1.5", false},
+		{"weights:4294967296", false},
+		{"top-percent:", false},
+		{"top-percent:0", false},
+		{"top-percent:101", false},
+		{"top-percent:-5", false},
+		{"top-percent:NaN", false},
+		{"top-percent:15:-1", false},
+		{"top-percent:15:11", false},
+		{"top-percent:15:1:2", false},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.s)
+		if tt.ok && err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.s, err)
+		}
+		if !tt.ok && err != ErrUnknownStructure {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.s, err, ErrUnknownStructure)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	tests := []struct {
+		s        Structure
+		entrants int
+		want     []uint64
+	}{
+		{WinnerTakesAll, 10, []uint64{1}},
+		{Top3, 10, []uint64{50, 30, 20}},
+		{Top3, 2, []uint64{50, 30}},
+		{"weights:3,1", 10, []uint64{3, 1}},
+		{"weights:5,4,3,2,1", 3, []uint64{5, 4, 3}},
+		// the paid places are rounded up, at least one
+		{"top-percent:15", 10, []uint64{1000000, 500000}},
+		{"top-percent:15", 20, []uint64{1000000, 500000, 333333}},
+		{"top-percent:10", 7, []uint64{1000000}},
+		{"top-percent:1", 1, []uint64{1000000}},
+		{"top-percent:50", 5, []uint64{1000000, 500000, 333333}},
+		{"top-percent:100", 2, []uint64{1000000, 500000}},
+		// the curve is the exponent the weights fall with
+		{"top-percent:30:1.5", 10, []uint64{1000000, 353553, 192450}},
+		{"top-percent:30:2", 10, []uint64{1000000, 250000, 111111}},
+		{"top-percent:30:0", 10, []uint64{1000000, 1000000, 1000000}},
+		{"top-percent:100:10", 3, []uint64{1000000, 977, 17}},
+		{"unknown", 10, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.s.Weights(tt.entrants); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q.Weights(%d) = %v, want %v", tt.s, tt.entrants, got, tt.want)
+		}
+	}
+}
+
+// Weights need not add up to 100, prizes are in proportion to them.
+func TestPrizes(t *testing.T) {
+	tests := []struct {
+		s        Structure
+		entrants int
+		pool     uint64
+		want     []uint64
+	}{
+		{Top3, 10, 1000, []uint64{500, 300, 200}},
+		{Top3, 2, 1000, []uint64{625, 375}},
+		{"weights:3,1", 10, 1000, []uint64{750, 250}},
+		{"weights:1,1,1", 10, 100, []uint64{34, 33, 33}},
+		{"weights:60,30", 10, 900, []uint64{600, 300}},
+		{"weights:0,1", 10, 100, []uint64{0, 100}},
+		{"top-percent:15", 10, 1500, []uint64{1000, 500}},
+		// the weight of third place is rounded down, so its prize is too
+		{"top-percent:50", 5, 1100, []uint64{601, 300, 199}},
+		{"top-percent:30:0", 10, 100, []uint64{34, 33, 33}},
+	}
+
+	for _, tt := range tests {
+		prizes := rounding.RemainderToPlayer.Split(tt.pool, tt.s.Weights(tt.entrants))
+		if !reflect.DeepEqual(prizes, tt.want) {
+			t.Errorf("prizes of %q for %d entrants of %d = %v, want %v", tt.s, tt.entrants, tt.pool, prizes, tt.want)
+		}
+	}
+}
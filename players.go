@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"net/http"
+	"time"
+)
+
+// RegisterPlayerHandler registers a player with a display name and optional
+// metadata. Without an id one is generated.
+func (h *Handlers) RegisterPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// balance and status are not taken from the request, points only come
+	// through the ledger
+	registration := struct {
+		Id       string            `json:"id"`
+		Name     string            `json:"name"`
+		Metadata map[string]string `json:"metadata"`
+	}{}
+
+	err := json.NewDecoder(r.Body).Decode(&registration)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println(err.Error())
+		return
+	}
+
+	if registration.Name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("no name provided")
+		return
+	}
+
+	if registration.Id == "" {
+		registration.Id = utils.NewId()
+	}
+
+	p := &types.Player{
+		Id:       registration.Id,
+		Name:     registration.Name,
+		Metadata: registration.Metadata,
+		Status:   types.PlayerActive,
+	}
+
+	err = h.store.CreatePlayer(p)
+	if err == storage.ErrExists {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("player already exists")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeProfile(w, p, 0)
+}
+
+// PlayerHandler reads the profile of a player on GET and changes its name or
+// metadata on PATCH.
+func (h *Handlers) PlayerHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		p, err := h.store.GetPlayer(mux.Vars(r)["id"])
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such player")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		escrow, err := h.store.GetAccountBalance(ledger.EscrowAccount(p.Id))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		writeProfile(w, p, escrow)
+	case http.MethodPatch:
+		h.updateProfile(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handlers) updateProfile(w http.ResponseWriter, r *http.Request) {
+	// fields left out stay as they are
+	changes := struct {
+		Name     *string           `json:"name"`
+		Metadata map[string]string `json:"metadata"`
+		// Status is only changed through PlayerStatusHandler.
+		Status *string `json:"status"`
+	}{}
+
+	err := json.NewDecoder(r.Body).Decode(&changes)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println(err.Error())
+		return
+	}
+
+	if changes.Name != nil && *changes.Name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid name given")
+		return
+	}
+
+	if changes.Status != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("status is changed through /players/{id}/status")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	pp, err := tx.GetPlayersForUpdate([]string{mux.Vars(r)["id"]})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(pp) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+	p := pp[0]
+
+	if changes.Name != nil {
+		p.Name = *changes.Name
+	}
+	if changes.Metadata != nil {
+		p.Metadata = changes.Metadata
+	}
+
+	if err = tx.UpdateProfile(p); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	escrow, err := tx.GetAccountBalance(ledger.EscrowAccount(p.Id))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeProfile(w, p, escrow)
+}
+
+// PlayerStatusHandler suspends, freezes, reinstates or closes a player. Every
+// change names the actor making it, suspending and freezing also a reason,
+// and a suspension the time it expires. Once a frozen player is reviewed what
+// it was paid in escrow is released to it, or forfeited to the house.
+func (h *Handlers) PlayerStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	change := struct {
+		Status  string     `json:"status"`
+		Reason  string     `json:"reason"`
+		Actor   string     `json:"actor"`
+		Until   *time.Time `json:"until"`
+		Forfeit bool       `json:"forfeit"`
+	}{}
+
+	err := json.NewDecoder(r.Body).Decode(&change)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	switch change.Status {
+	case types.PlayerActive, types.PlayerClosed:
+		change.Until = nil
+	case types.PlayerSuspended:
+		if change.Until == nil || !change.Until.After(now) {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("a suspension needs an expiry in the future")
+			return
+		}
+	case types.PlayerFrozen:
+		change.Until = nil
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid status given")
+		return
+	}
+
+	if change.Actor == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("no actor provided")
+		return
+	}
+
+	if change.Reason == "" && (change.Status == types.PlayerSuspended || change.Status == types.PlayerFrozen) {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("no reason provided")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	pp, err := tx.GetPlayersForUpdate([]string{mux.Vars(r)["id"]})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(pp) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+	p := pp[0]
+
+	reviewed := p.Status == types.PlayerFrozen && change.Status != types.PlayerFrozen
+	if change.Forfeit && !reviewed {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("only the escrow of a frozen player can be forfeited")
+		return
+	}
+
+	escrow, err := tx.GetAccountBalance(ledger.EscrowAccount(p.Id))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if reviewed && escrow > 0 {
+		credit, reason := ledger.Player(p), ledger.ReasonRelease
+		if change.Forfeit {
+			credit, reason = ledger.House, ledger.ReasonForfeit
+		}
+
+		err = ledger.Transfer(tx, ledger.Escrow(p.Id), credit, uint64(escrow), reason, change.Actor)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.UpdatePlayer(p); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		escrow = 0
+	}
+
+	p.Status, p.StatusReason, p.StatusBy, p.StatusUntil = change.Status, change.Reason, change.Actor, change.Until
+
+	if err = tx.UpdateProfile(p); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeProfile(w, p, escrow)
+}
+
+// restricted tells why p may not move points at now, nil when it may.
+func restricted(p *types.Player, now time.Time) error {
+	if status := p.StatusAt(now); status != types.PlayerActive {
+		return errors.New("player " + p.Id + " is " + status)
+	}
+
+	return nil
+}
+
+// payee is the account what p wins is paid to: its own, or its escrow while
+// it is frozen.
+func payee(p *types.Player) ledger.Account {
+	if p.Status == types.PlayerFrozen {
+		return ledger.Escrow(p.Id)
+	}
+
+	return ledger.Player(p)
+}
+
+// writeProfile writes p along with the points it holds in escrow.
+func writeProfile(w http.ResponseWriter, p *types.Player, escrow int64) {
+	if p.Metadata == nil {
+		p.Metadata = map[string]string{}
+	}
+
+	j, err := json.Marshal(struct {
+		*types.Player
+		Escrow int64 `json:"escrow"`
+	}{p, escrow})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
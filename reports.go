@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// RakeReportHandler sums the rake the house took per tournament, net of the
+// rake refunded by cancellations. It can be limited to a tournament and to
+// a period of time.
+func (h *Handlers) RakeReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	f := types.LedgerFilter{
+		Account: ledger.HouseAccount,
+		Reasons: []string{ledger.ReasonRake, ledger.ReasonRefund},
+	}
+
+	var err error
+	if params.Get("from") != "" {
+		if f.From, err = utils.GetTimeURLParam(params, "from"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid from given")
+			return
+		}
+	}
+
+	if params.Get("to") != "" {
+		if f.To, err = utils.GetTimeURLParam(params, "to"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid to given")
+			return
+		}
+	}
+
+	tournamentId := params.Get("tournamentId")
+
+	entries, err := h.store.ListLedgerEntries(f)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	raked := make(map[string]int64)
+	for _, e := range entries {
+		if tournamentId != "" && e.Reference != tournamentId {
+			continue
+		}
+
+		if e.Credit == f.Account {
+			raked[e.Reference] += int64(e.Amount)
+		} else {
+			raked[e.Reference] -= int64(e.Amount)
+		}
+	}
+
+	type tournament struct {
+		TournamentId string `json:"tournamentId"`
+		Rake         int64  `json:"rake"`
+	}
+
+	resp := struct {
+		Total       int64        `json:"total"`
+		Tournaments []tournament `json:"tournaments"`
+	}{Tournaments: []tournament{}}
+
+	for id, rake := range raked {
+		resp.Total += rake
+		resp.Tournaments = append(resp.Tournaments, tournament{id, rake})
+	}
+
+	sort.Slice(resp.Tournaments, func(i, j int) bool {
+		return resp.Tournaments[i].TournamentId < resp.Tournaments[j].TournamentId
+	})
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
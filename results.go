@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"github.com/xfreshx/lifland/payouts"
+	"github.com/xfreshx/lifland/types"
+	"sort"
+)
+
+// winner is a player paid in a tournament result.
+type winner struct {
+	PlayerId string `json:"playerId"`
+	// Entry is the number of the entry of the player, it may be left out
+	// for players who entered once.
+	Entry int    `json:"entry"`
+	Prize uint64 `json:"prize"`
+	// Rank is the place the player finished at, 0 when not ranked.
+	Rank int `json:"-"`
+}
+
+// placement is a player finishing a tournament at a rank, 1 being the best.
+// Players sharing a rank are tied. Prize is only read for tournaments
+// without a payout structure.
+type placement struct {
+	PlayerId string `json:"playerId"`
+	Entry    int    `json:"entry"`
+	Rank     int    `json:"rank"`
+	Prize    uint64 `json:"prize"`
+}
+
+// entryOf finds the entry of a player by its number. The number may be 0
+// for a player with a single entry.
+func entryOf(t *types.Tournament, playerId string, number int) (*types.Entry, error) {
+	entries := t.PlayerEntries(playerId)
+	if len(entries) == 0 {
+		return nil, errors.New("no such player registered in the tournament")
+	}
+
+	if number == 0 {
+		if len(entries) > 1 {
+			return nil, errors.New("entry must be given for a player who entered more than once")
+		}
+		return entries[0], nil
+	}
+
+	if e, ok := t.Entries[types.EntryKey(playerId, number)]; ok {
+		return e, nil
+	}
+
+	return nil, errors.New("no such entry in the tournament")
+}
+
+// orderPlacements ranks players by a finishing order, best first, with no
+// ties. Players who entered more than once need placements instead.
+func orderPlacements(order []string) []placement {
+	placements := make([]placement, len(order))
+	for i, id := range order {
+		placements[i] = placement{PlayerId: id, Rank: i + 1}
+	}
+
+	return placements
+}
+
+// rankPrizes computes the winners of a tournament from ranked placements.
+// Every placement takes the prize of a place in rank order: from the payout
+// structure of the tournament applied to its pool when it has one, from the placement itself
+// otherwise. Tied players then pool the prizes of their places and split them
+// equally by the rounding policy.
+func rankPrizes(t *types.Tournament, placements []placement) ([]winner, error) {
+	placements = append([]placement(nil), placements...)
+
+	seen := make(map[string]bool)
+	for i, p := range placements {
+		e, err := entryOf(t, p.PlayerId, p.Entry)
+		if err != nil {
+			return nil, err
+		}
+		if seen[e.Key()] {
+			return nil, errors.New("placements must list entries of the tournament once")
+		}
+		if p.Rank < 1 {
+			return nil, errors.New("placements must be ranked from 1")
+		}
+		seen[e.Key()] = true
+		placements[i].Entry = e.Number
+	}
+
+	sort.SliceStable(placements, func(i, j int) bool {
+		a, b := placements[i], placements[j]
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		if a.PlayerId != b.PlayerId {
+			return a.PlayerId < b.PlayerId
+		}
+		return a.Entry < b.Entry
+	})
+
+	policy := policyOf(t)
+
+	places := make([]uint64, len(placements))
+	if t.Payout != "" {
+		weights := payouts.Structure(t.Payout).Weights(len(t.Entries))
+		if len(placements) < len(weights) {
+			return nil, errors.New("placements do not cover the paid places")
+		}
+
+		copy(places, policy.Split(t.Pool(), weights))
+	} else {
+		for i, p := range placements {
+			places[i] = p.Prize
+		}
+	}
+
+	winners := make([]winner, len(placements))
+	for i := 0; i < len(placements); {
+		// placements i to j-1 share a rank
+		var pooled uint64
+		j := i
+		for ; j < len(placements) && placements[j].Rank == placements[i].Rank; j++ {
+			if pooled+places[j] < pooled {
+				return nil, errors.New("prizes are too large")
+			}
+			pooled += places[j]
+		}
+
+		ties := make([]uint64, j-i)
+		for k := range ties {
+			ties[k] = 1
+		}
+
+		for k, prize := range policy.Split(pooled, ties) {
+			p := placements[i+k]
+			winners[i+k] = winner{PlayerId: p.PlayerId, Entry: p.Entry, Prize: prize, Rank: p.Rank}
+		}
+
+		i = j
+	}
+
+	return winners, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// roundings returns the rounding policies recorded on the transactions of a
+// player, by type.
+func (s *testServer) roundings(playerId string) map[string]interface{} {
+	s.t.Helper()
+
+	roundings := make(map[string]interface{})
+	for _, tr := range s.get("/players/"+playerId+"/transactions", http.StatusOK)["transactions"].([]interface{}) {
+		tr := tr.(map[string]interface{})
+		roundings[tr["type"].(string)] = tr["rounding"]
+	}
+
+	return roundings
+}
+
+func TestTiedPrizes(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c", "d")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100&rounding=bankers", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=d", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=b&backerId=d&backerAmount=30", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+
+	// a and b tie for first and split what its place and the next pay
+	s.post("/resultTournament", `{"tournamentId":"t1","placements":[
+		{"playerId":"a","rank":1,"prize":201},
+		{"playerId":"b","rank":1,"prize":98},
+		{"playerId":"c","rank":3,"prize":1}]}`, http.StatusOK)
+
+	tt := s.get("/tournaments/t1", http.StatusOK)
+	for _, e := range tt["entries"].([]interface{}) {
+		e := e.(map[string]interface{})
+		if want := map[string]float64{"a": 150, "b": 149, "c": 1}[e["playerId"].(string)]; e["prize"] != want {
+			t.Errorf("player %v won %v, want %v", e["playerId"], e["prize"], want)
+		}
+	}
+
+	// split prizes and deposits record the policy they were split by
+	if r := s.roundings("a"); r["prize"] != "bankers" || r["backing"] != "bankers" {
+		t.Errorf("transactions of a are rounded %v", r)
+	}
+	if r := s.roundings("b"); r["prize"] != "bankers" || r["backing"] != nil {
+		t.Errorf("transactions of b are rounded %v", r)
+	}
+}
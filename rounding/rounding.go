@@ -0,0 +1,122 @@
+package rounding
+
+import (
+	"errors"
+	"math/bits"
+	"sort"
+)
+
+// Policy decides who gets the points left over when an amount is divided
+// in integer shares. Every policy hands out the amount exactly.
+type Policy string
+
+const (
+	// RemainderToPlayer gives the leftover to the first share, which is the
+	// player in deposit and prize splits.
+	RemainderToPlayer Policy = "remainder-to-player"
+	// RemainderToLargest gives the leftover to the largest stake, the first
+	// one of them on a tie.
+	RemainderToLargest Policy = "remainder-to-largest"
+	// Bankers rounds every share half to even and settles the difference
+	// by the largest fractional parts.
+	Bankers Policy = "bankers"
+)
+
+const Default = RemainderToPlayer
+
+var ErrUnknownPolicy = errors.New("unknown rounding policy")
+
+func Parse(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case RemainderToPlayer, RemainderToLargest, Bankers:
+		return p, nil
+	}
+
+	return "", ErrUnknownPolicy
+}
+
+// Split divides points in proportion to weights. The shares always add up to
+// points; with no weight at all everything goes to the first share.
+func (p Policy) Split(points uint64, weights []uint64) []uint64 {
+	shares := make([]uint64, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+
+	var total uint64
+	for _, w := range weights {
+		total += w
+	}
+
+	if total == 0 {
+		shares[0] = points
+		return shares
+	}
+
+	// rems[i]/total is the fractional part of the exact share
+	rems := make([]uint64, len(weights))
+	var sum uint64
+	for i, w := range weights {
+		hi, lo := bits.Mul64(points, w)
+		shares[i], rems[i] = bits.Div64(hi, lo, total)
+		sum += shares[i]
+	}
+
+	left := points - sum
+
+	switch p {
+	case RemainderToLargest:
+		largest := 0
+		for i, w := range weights {
+			if w > weights[largest] {
+				largest = i
+			}
+		}
+		shares[largest] += left
+	case Bankers:
+		bankers(shares, rems, total, left)
+	default:
+		shares[0] += left
+	}
+
+	return shares
+}
+
+func bankers(shares, rems []uint64, total, left uint64) {
+	up := make([]bool, len(shares))
+	var rounded uint64
+
+	for i, r := range rems {
+		half := total - r
+		if r > half || r == half && shares[i]%2 == 1 {
+			shares[i]++
+			up[i] = true
+			rounded++
+		}
+	}
+
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rems[order[a]] > rems[order[b]] })
+
+	// too many shares went up: take back from the smallest fractions
+	for k := len(order) - 1; k >= 0 && rounded > left; k-- {
+		if i := order[k]; up[i] {
+			shares[i]--
+			rounded--
+		}
+	}
+
+	// too few: add to the largest fractions that went down
+	for _, i := range order {
+		if rounded >= left {
+			break
+		}
+		if !up[i] {
+			shares[i]++
+			rounded++
+		}
+	}
+}
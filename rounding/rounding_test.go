@@ -0,0 +1,87 @@
+package rounding
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSplitSum(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  uint64
+		weights []uint64
+	}{
+		{"even", 100, []uint64{50, 50}},
+		{"thirds", 100, []uint64{1, 1, 1}},
+		{"uneven", 97, []uint64{3, 5, 7, 11}},
+		{"zero points", 0, []uint64{1, 2}},
+		{"zero weights", 100, []uint64{0, 0, 0}},
+		{"some zero weights", 101, []uint64{0, 1, 0, 1}},
+		{"single weight", 101, []uint64{7}},
+		{"single zero weight", 101, []uint64{0}},
+		{"more shares than points", 3, []uint64{1, 1, 1, 1, 1, 1, 1}},
+		{"large points", math.MaxUint64, []uint64{1, 2, 3}},
+		{"large weights", 1000003, []uint64{math.MaxUint64 / 4, math.MaxUint64 / 4, 1}},
+		{"large both", math.MaxUint64 - 1, []uint64{math.MaxUint32, math.MaxUint32 - 1, 3}},
+	}
+
+	for _, p := range []Policy{RemainderToPlayer, RemainderToLargest, Bankers} {
+		for _, tt := range tests {
+			t.Run(string(p)+"/"+tt.name, func(t *testing.T) {
+				shares := p.Split(tt.points, tt.weights)
+				if len(shares) != len(tt.weights) {
+					t.Fatalf("got %d shares for %d weights", len(shares), len(tt.weights))
+				}
+
+				var sum uint64
+				for _, s := range shares {
+					sum += s
+				}
+				if sum != tt.points {
+					t.Errorf("shares %v add up to %d, want %d", shares, sum, tt.points)
+				}
+			})
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		points  uint64
+		weights []uint64
+		want    []uint64
+	}{
+		{RemainderToPlayer, 100, []uint64{1, 1, 1}, []uint64{34, 33, 33}},
+		{RemainderToPlayer, 100, []uint64{0, 0}, []uint64{100, 0}},
+		{RemainderToLargest, 100, []uint64{1, 2, 1}, []uint64{25, 50, 25}},
+		{RemainderToLargest, 10, []uint64{1, 1, 1}, []uint64{4, 3, 3}},
+		{RemainderToLargest, 10, []uint64{1, 2, 2}, []uint64{2, 4, 4}},
+		{RemainderToLargest, 11, []uint64{1, 2, 2}, []uint64{2, 5, 4}},
+		{Bankers, 10, []uint64{1, 1, 1, 1}, []uint64{3, 3, 2, 2}},
+		{Bankers, 10, []uint64{3, 3, 4}, []uint64{3, 3, 4}},
+		{Bankers, 5, []uint64{1, 1}, []uint64{3, 2}},
+		{Bankers, 100, []uint64{1, 1, 1}, []uint64{34, 33, 33}},
+		{Bankers, 7, []uint64{1}, []uint64{7}},
+		{Bankers, 0, nil, []uint64{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Split(tt.points, tt.weights); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.Split(%d, %v) = %v, want %v", tt.policy, tt.points, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"remainder-to-player", "remainder-to-largest", "bankers"} {
+		if p, err := Parse(s); err != nil || string(p) != s {
+			t.Errorf("Parse(%q) = %q, %v", s, p, err)
+		}
+	}
+
+	if _, err := Parse("up"); err != ErrUnknownPolicy {
+		t.Errorf("Parse(\"up\") error = %v, want %v", err, ErrUnknownPolicy)
+	}
+}
@@ -0,0 +1,623 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/lifecycle"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"math"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// OfferStakeHandler posts an offer of a player selling a percent of its
+// entry in a tournament, for a markup percent on top of the stake, until a
+// deadline. A player who already joined or has an open offer can not post
+// another.
+func (h *Handlers) OfferStakeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	tournamentId, err := utils.GetStringURLParam(params, "tournamentId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid tournamentId given")
+		return
+	}
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	share, err := getBasisPointsURLParam(params, "percent")
+	if err != nil || share == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid percent given")
+		return
+	}
+
+	var markup uint64
+	if params.Get("markup") != "" {
+		if markup, err = getBasisPointsURLParam(params, "markup"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid markup given")
+			return
+		}
+	}
+
+	now := time.Now()
+
+	deadline, err := utils.GetTimeURLParam(params, "deadline")
+	if err != nil || !deadline.After(now) {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid deadline given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	// locked so that the player can not join while the offer is posted
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if t.State == lifecycle.Resulted || t.State == lifecycle.Cancelled {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament is " + t.State)
+		return
+	}
+
+	p, err := tx.GetPlayersForUpdate([]string{playerId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if err = restricted(p[0], now); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	// stakes are sold before the player joins, one offer at a time
+	if len(t.PlayerEntries(p[0].Id)) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("player has already joined the tournament")
+		return
+	}
+
+	offers, err := tx.ListOffers(t.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	for _, o := range offers {
+		if o.PlayerId == p[0].Id && o.StatusAt(now) == types.OfferOpen {
+			w.WriteHeader(http.StatusConflict)
+			log.Println("player already has an open offer " + o.Id)
+			return
+		}
+	}
+
+	o := &types.Offer{
+		Id:           utils.NewId(),
+		TournamentId: t.Id,
+		PlayerId:     p[0].Id,
+		Share:        share,
+		Markup:       markup,
+		Deadline:     deadline,
+		Status:       types.OfferOpen,
+	}
+
+	if err = tx.AddOffer(o); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeOffer(w, o, now)
+}
+
+// BuyStakeHandler buys a percent of the entry offered by offerId for
+// backerId. The stake at the current deposit of the tournament and its markup
+// are held from the backer until the deadline of the offer.
+func (h *Handlers) BuyStakeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	offerId, err := utils.GetStringURLParam(params, "offerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid offerId given")
+		return
+	}
+
+	backerId, err := utils.GetStringURLParam(params, "backerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid backerId given")
+		return
+	}
+
+	share, err := getBasisPointsURLParam(params, "percent")
+	if err != nil || share == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid percent given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	o, err := tx.GetOfferForUpdate(offerId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such offer")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+	if status := o.StatusAt(now); status != types.OfferOpen {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("offer is " + status)
+		return
+	}
+
+	if backerId == o.PlayerId {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid backerId given")
+		return
+	}
+
+	if o.Sold()+share > o.Share {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("offer has only " + strconv.FormatUint(o.Share-o.Sold(), 10) + " basis points left")
+		return
+	}
+
+	t, err := tx.GetTournament(o.TournamentId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// a share is only worth holding points for while the player may still join
+	if err = joinable(t, o.PlayerId, now); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println(err.Error())
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	b, err := tx.GetPlayersForUpdate([]string{backerId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(b) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such backer")
+		return
+	}
+
+	if err = restricted(b[0], now); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	purchase := &types.Purchase{
+		OfferId:  o.Id,
+		BackerId: b[0].Id,
+		Share:    share,
+		Amount:   basisPoints(t.Deposit, share),
+	}
+	purchase.Premium = basisPoints(purchase.Amount, o.Markup)
+
+	if purchase.Amount == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("backer contribution must be positive")
+		return
+	}
+
+	if b[0].Available() < purchase.Amount+purchase.Premium {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("backer has insufficient points")
+		return
+	}
+
+	hold := &types.Hold{
+		Id:        utils.NewId(),
+		PlayerId:  b[0].Id,
+		Amount:    purchase.Amount + purchase.Premium,
+		Status:    types.HoldActive,
+		ExpiresAt: o.Deadline,
+	}
+
+	if err = tx.AddHold(hold); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	purchase.HoldId = hold.Id
+
+	err = tx.AddPurchase(purchase)
+	if err == storage.ErrExists {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("backer already bought a share of the offer")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	o.Purchases = append(o.Purchases, purchase)
+	writeOffer(w, o, now)
+}
+
+// CancelOfferHandler withdraws the open offer given by offerId of playerId
+// and releases what its backers had held.
+func (h *Handlers) CancelOfferHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	offerId, err := utils.GetStringURLParam(params, "offerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid offerId given")
+		return
+	}
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	o, err := tx.GetOfferForUpdate(offerId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such offer")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if playerId != o.PlayerId {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println("offer belongs to another player")
+		return
+	}
+
+	if o.Status != types.OfferOpen {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("offer is " + o.Status)
+		return
+	}
+
+	var backersId []string
+	for _, purchase := range o.Purchases {
+		backersId = append(backersId, purchase.BackerId)
+	}
+
+	// backers are locked before their holds
+	backers, err := tx.GetPlayersForUpdate(backersId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	// purchases are stored in key order, not in the order players are
+	// returned, so backers are looked up by id
+	byId := make(map[string]*types.Player)
+	for _, b := range backers {
+		byId[b.Id] = b
+	}
+
+	now := time.Now()
+
+	for _, purchase := range o.Purchases {
+		b, ok := byId[purchase.BackerId]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such backer " + purchase.BackerId)
+			return
+		}
+
+		hold, err := tx.GetHoldForUpdate(purchase.HoldId)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		// a hold past the deadline has already expired
+		if hold.StatusAt(now) != types.HoldActive {
+			continue
+		}
+
+		if err = capture(tx, b, hold, 0, now); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	o.Status = types.OfferCancelled
+
+	if err = tx.UpdateOffer(o); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeOffer(w, o, now)
+}
+
+// OfferHandler returns an offer with the shares bought of it.
+func (h *Handlers) OfferHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	o, err := h.store.GetOffer(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such offer")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeOffer(w, o, time.Now())
+}
+
+// TournamentOffersHandler lists the offers posted for a tournament, oldest
+// first.
+func (h *Handlers) TournamentOffersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	tournamentId := mux.Vars(r)["id"]
+
+	if _, err := h.store.GetTournament(tournamentId); err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	oo, err := h.store.ListOffers(tournamentId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	now := time.Now()
+	for _, o := range oo {
+		o.Status = o.StatusAt(now)
+	}
+
+	j, err := json.Marshal(struct {
+		Offers []*types.Offer `json:"offers"`
+	}{oo})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// takeUp checks that playerId may join tournament t with the offer o at
+// now, and returns the backings its purchases turn into.
+func takeUp(o *types.Offer, t *types.Tournament, playerId string, now time.Time) ([]*types.Backing, error) {
+	if o.TournamentId != t.Id || o.PlayerId != playerId {
+		return nil, errors.New("offer is not for this entry")
+	}
+
+	if status := o.StatusAt(now); status != types.OfferOpen {
+		return nil, errors.New("offer is " + status)
+	}
+
+	bb := make([]*types.Backing, 0, len(o.Purchases))
+	var total uint64
+	for _, purchase := range o.Purchases {
+		// the deposit may have been lowered since the shares were bought
+		if purchase.Amount > t.Deposit-total {
+			return nil, errors.New("backers contribute more than the deposit")
+		}
+
+		bb = append(bb, &types.Backing{PlayerId: playerId, BackerId: purchase.BackerId, Amount: purchase.Amount, Premium: purchase.Premium})
+		total += purchase.Amount
+	}
+
+	return bb, nil
+}
+
+// releaseMarkups pays the players of a resulted tournament the markups their
+// backers paid, escrowed by the tournament since they joined.
+func releaseMarkups(tx storage.Tx, t *types.Tournament) error {
+	markups := make(map[string]uint64)
+	for _, e := range t.Entries {
+		for _, b := range e.Backings {
+			if b.Premium > 0 {
+				markups[e.PlayerId] += b.Premium
+			}
+		}
+	}
+
+	if len(markups) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(markups))
+	for id := range markups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	players, err := tx.GetPlayersForUpdate(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range players {
+		err = ledger.Transfer(tx, ledger.Tournament(t.Id), payee(p), markups[p.Id], ledger.ReasonMarkup, t.Id)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.UpdatePlayer(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// basisPoints is the part of points given in hundredths of a percent, at
+// most 10000 of them, rounded down.
+func basisPoints(points, bp uint64) uint64 {
+	hi, lo := bits.Mul64(points, bp)
+	part, _ := bits.Div64(hi, lo, 10000)
+
+	return part
+}
+
+// getBasisPointsURLParam reads a percent between 0 and 100 as hundredths of
+// a percent.
+func getBasisPointsURLParam(params url.Values, name string) (uint64, error) {
+	percent, err := strconv.ParseFloat(params.Get(name), 64)
+	if err != nil {
+		return 0, err
+	}
+	if !(percent >= 0 && percent <= 100) {
+		return 0, errors.New("percent out of range")
+	}
+
+	return uint64(math.Round(percent * 100)), nil
+}
+
+func writeOffer(w http.ResponseWriter, o *types.Offer, now time.Time) {
+	o.Status = o.StatusAt(now)
+
+	j, err := json.Marshal(o)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
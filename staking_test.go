@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStakeOffers(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&markup=20&deadline="+deadline(), http.StatusOK)
+	s.get("/offerStake?tournamentId=t1&playerId=a&percent=10&deadline="+deadline(), http.StatusConflict)
+
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b&percent=30", http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=c&percent=30", http.StatusConflict)
+	s.balances(map[string][2]float64{"b": {1000, 964}})
+
+	s.get("/joinTournament?tournamentId=t1&playerId=a&offerId="+offer["id"].(string), http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusOK)
+	s.get("/offerStake?tournamentId=t1&playerId=c&percent=10&deadline="+deadline(), http.StatusConflict)
+
+	// the markup is escrowed until the result
+	s.balances(map[string][2]float64{"a": {930, 930}, "b": {964, 964}, "c": {900, 900}})
+
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":200}]}`, http.StatusOK)
+
+	s.balances(map[string][2]float64{"a": {1076, 1076}, "b": {1024, 1024}, "c": {900, 900}})
+}
+
+func TestCancelRefundsMarkup(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&markup=20&deadline="+deadline(), http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b&percent=50", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&offerId="+offer["id"].(string), http.StatusOK)
+	s.balances(map[string][2]float64{"a": {950, 950}, "b": {940, 940}})
+
+	s.get("/cancelTournament?tournamentId=t1", http.StatusOK)
+	s.balances(map[string][2]float64{"a": {1000, 1000}, "b": {1000, 1000}})
+}
+
+func TestCancelOfferReleasesHolds(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&deadline="+deadline(), http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b&percent=50", http.StatusOK)
+	s.balances(map[string][2]float64{"b": {1000, 950}})
+
+	s.get("/cancelStake?offerId="+offer["id"].(string)+"&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&offerId="+offer["id"].(string), http.StatusConflict)
+	s.balances(map[string][2]float64{"a": {1000, 1000}, "b": {1000, 1000}})
+
+	// the player may offer again once the offer is cancelled
+	s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&deadline="+deadline(), http.StatusOK)
+}
+
+// Purchases are kept in the order of their escaped keys, "b%2F" before "b-",
+// and players in the order of their ids, "b-" before "b/".
+func TestOfferBackersInKeyOrder(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b-", "b/")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&markup=20&deadline="+deadline(), http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b%2F&percent=30", http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b-&percent=10", http.StatusOK)
+	s.balances(map[string][2]float64{"b-": {1000, 988}, "b/": {1000, 964}})
+
+	s.get("/joinTournament?tournamentId=t1&playerId=a&offerId="+offer["id"].(string), http.StatusOK)
+	s.balances(map[string][2]float64{"a": {940, 940}, "b-": {988, 988}, "b/": {964, 964}})
+
+	for _, p := range s.get("/offers/"+offer["id"].(string), http.StatusOK)["purchases"].([]interface{}) {
+		p := p.(map[string]interface{})
+		hold := s.get("/holds/"+p["holdId"].(string), http.StatusOK)
+		if hold["playerId"] != p["backerId"] || hold["captured"] != p["amount"].(float64)+p["premium"].(float64) {
+			t.Errorf("hold of %v is %v", p["backerId"], hold)
+		}
+	}
+}
+
+func TestCancelOfferInKeyOrder(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b-", "b/")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&deadline="+deadline(), http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b%2F&percent=30", http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b-&percent=10", http.StatusOK)
+
+	s.get("/cancelStake?offerId="+offer["id"].(string)+"&playerId=a", http.StatusOK)
+	for _, p := range s.get("/offers/"+offer["id"].(string), http.StatusOK)["purchases"].([]interface{}) {
+		p := p.(map[string]interface{})
+		if hold := s.get("/holds/"+p["holdId"].(string), http.StatusOK); hold["status"] != "released" {
+			t.Errorf("hold of %v is %v, want released", p["backerId"], hold["status"])
+		}
+	}
+	s.balances(map[string][2]float64{"b-": {1000, 1000}, "b/": {1000, 1000}})
+}
+
+func TestBuyStakeOnlyWhileJoinable(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100&maxEntrants=1", http.StatusOK)
+	offer := s.get("/offerStake?tournamentId=t1&playerId=a&percent=50&deadline="+deadline(), http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusOK)
+
+	if reason := string(s.send(http.MethodGet, "/buyStake?offerId="+offer["id"].(string)+"&backerId=b&percent=10", nil, http.StatusConflict)); reason != "tournament is full" {
+		t.Errorf("purchase is refused with %q", reason)
+	}
+
+	s.get("/announceTournament?tournamentId=t2&deposit=100", http.StatusOK)
+	offer = s.get("/offerStake?tournamentId=t2&playerId=a&percent=50&deadline="+deadline(), http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t2", http.StatusOK)
+	s.get("/buyStake?offerId="+offer["id"].(string)+"&backerId=b&percent=10", http.StatusConflict)
+
+	s.balances(map[string][2]float64{"b": {1000, 1000}})
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"github.com/dgraph-io/badger"
+)
+
+// NewBadgerStore opens an embedded Badger database in the given directory.
+// Players and tournaments are stored as JSON under "player/" and "tournament/" keys.
+func NewBadgerStore(dir string) (Store, error) {
+	opts := badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newKVStore(&badgerEngine{db: db})
+	if err = s.migrateLegacy(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type badgerEngine struct {
+	db *badger.DB
+}
+
+type badgerTxn struct {
+	txn *badger.Txn
+}
+
+func (e *badgerEngine) Begin() kvTxn {
+	return &badgerTxn{txn: e.db.NewTransaction(true)}
+}
+
+func (e *badgerEngine) Reset() error {
+	return e.db.DropAll()
+}
+
+func (e *badgerEngine) Close() error {
+	return e.db.Close()
+}
+
+func (t *badgerTxn) Get(key string) ([]byte, error) {
+	item, err := t.txn.Get([]byte(key))
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item.ValueCopy(nil)
+}
+
+func (t *badgerTxn) Set(key string, value []byte) error {
+	return t.txn.Set([]byte(key), value)
+}
+
+func (t *badgerTxn) Delete(key string) error {
+	return t.txn.Delete([]byte(key))
+}
+
+func (t *badgerTxn) Scan(prefix string, fn func(key string, value []byte) error) error {
+	it := t.txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	p := []byte(prefix)
+	for it.Seek(p); it.ValidForPrefix(p); it.Next() {
+		item := it.Item()
+
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(string(item.KeyCopy(nil)), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *badgerTxn) Commit() error {
+	return t.txn.Commit()
+}
+
+func (t *badgerTxn) Discard() {
+	t.txn.Discard()
+}
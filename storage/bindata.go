@@ -1,6 +1,25 @@
 // Code generated by go-bindata.
 // sources:
 // migrations/0001_initial.sql
+// migrations/0002_ledger.sql
+// migrations/0003_entries_backings.sql
+// migrations/0004_entry_contribution.sql
+// migrations/0005_rounding.sql
+// migrations/0006_tournament_state.sql
+// migrations/0007_tournament_history.sql
+// migrations/0008_overlay.sql
+// migrations/0009_payout_structure.sql
+// migrations/0010_entry_rank.sql
+// migrations/0011_rake.sql
+// migrations/0012_guarantee.sql
+// migrations/0013_registration_limits.sql
+// migrations/0014_reentries.sql
+// migrations/0015_player_profiles.sql
+// migrations/0016_player_status.sql
+// migrations/0017_transfers.sql
+// migrations/0018_holds.sql
+// migrations/0019_offers.sql
+// migrations/0020_backing_premiums.sql
 // DO NOT EDIT!
 
 package storage
@@ -88,6 +107,386 @@ func migrations0001_initialSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations0002_ledgerSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6c\x52\xcb\x8e\xdb\x30\x10\x3b\x5b\x5f\xc1\x9b\x63\xd4\x01\x7a\x6e\x80\xfe\x45\xcf\x81\x6c\x31\xc9\x20\xf2\xc8\x18\x4d\xb0\x49\xb1\x1f\x5f\x78\xed\x4d\xb1\x8f\x9b\x30\x24\x87\x22\xa5\xfd\x1e\x3f\x26\x39\x5b\x74\xe2\xcf\x1c\x46\xe3\x72\xf2\x38\x64\x22\x33\x9d\x69\xd8\x85\x46\x12\x06\x39\x57\x9a\xc4\x8c\xd9\x64\x8a\xf6\xc0\x95\x8f\x3e\x34\x89\x83\x38\x9c\x77\x87\x16\x87\xde\x72\xee\x43\x33\x1a\xd3\x37\xe3\x38\x95\x9b\xfa\xb2\x4b\xf4\x3f\x80\xf1\xc2\xf1\x8a\xdd\x86\xfe\xc6\xcf\xae\x0f\x8d\x31\xd6\xa2\x5f\x56\x18\x4f\x34\xea\xc8\x8f\x08\x12\x4f\xf1\x96\x1d\x6d\xbb\xda\x47\x67\x3a\x46\x87\xcb\xc4\xea\x71\x9a\xfd\xef\x57\xb2\x96\x97\x5d\x17\xba\x43\x78\x0f\x2e\x9a\x78\xdf\x82\x1f\xdf\xa2\x1d\x25\xdd\x51\xf4\x59\xc6\xdb\xb0\x87\xa4\xee\xf0\xad\x68\x4d\xfe\x59\xb5\x4e\x37\x59\xd8\xef\x31\xc4\x1c\x75\x64\x05\xef\x52\x5d\xf4\x8c\x81\xa7\x62\x84\x5f\x9e\xc5\x47\x23\x86\x52\xae\x4c\x88\x15\x65\xa6\x2e\x3c\xaa\x9b\xb0\x06\xd1\x4a\x73\x88\x7a\xf9\x7c\xb9\x77\xb7\xb5\xd0\x1e\x6b\x95\x3d\x9e\xdd\x75\xa1\xa9\xcc\x1c\x1d\xed\x18\xeb\x45\x68\x6d\x8f\x76\xce\xf1\x41\xfb\xd5\xe2\xf5\x15\x92\x7a\xcc\x45\xd4\x6b\x8f\x76\xb3\x5e\x38\xeb\x5f\x91\xa2\x6d\x68\x4e\x56\x26\xac\xa2\x1a\x9a\x97\x0b\x8d\x9b\x66\x79\xc3\x43\xf8\x37\x00\x8c\x35\x9d\x30\x5c\x02\x00\x00")
+
+func migrations0002_ledgerSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0002_ledgerSql,
+		"migrations/0002_ledger.sql",
+	)
+}
+
+func migrations0002_ledgerSql() (*asset, error) {
+	bytes, err := migrations0002_ledgerSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0002_ledger.sql", size: 604, mode: os.FileMode(420), modTime: time.Unix(1792210730, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0003_entries_backingsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x94\x55\xcd\x92\x9b\x3c\x10\x3c\x4b\x4f\xd1\x37\xc3\xb7\x98\x2f\x39\xbb\xfc\x18\x39\xbb\x04\x0c\x46\x6b\x90\x88\x34\xd4\x9a\x4a\xe5\xdd\x53\xe2\x1f\x3b\x9b\xdd\xbd\xd9\x9a\xe9\xd6\x4c\x77\xcb\x3e\x1e\xf1\xd2\xe8\xab\x53\x4c\xf8\xd1\xca\xdc\x51\xf8\xc4\x2a\xab\x09\x6c\x3b\x67\x54\x43\x86\x2f\x64\xd8\x69\xf2\x88\xa4\xd8\x9c\xea\x02\x4c\x77\x86\xb1\x0c\xd3\xd5\x35\x1c\x95\xe4\xc8\xe4\xe4\x37\x60\x8f\x48\x17\x31\xac\x41\x41\x35\x31\x21\x57\x3e\x57\x05\x25\x52\xb4\xb5\xea\xc9\xfd\x93\x67\x6c\x19\x39\x02\xc2\xe9\x46\xb9\x1e\x37\xea\x11\xed\x46\x49\xb0\xb0\xc5\x32\x3e\xc9\xfd\x2e\x99\xca\x6f\xda\x5c\x3f\xda\xe0\xfd\x99\x12\x29\x02\xc7\xd7\xa6\x55\x8d\xed\x0c\x23\xd3\x57\x6d\x36\x80\x82\x4a\xd5\xd5\x8c\x6f\x9f\x5d\x28\xc1\x72\x77\xa0\x2d\xad\x23\x7d\x35\x1f\x89\x20\xc4\x5f\xfd\x58\xcd\x7c\x17\xfa\x6c\xd6\x56\x51\x6d\x0a\xba\x2f\x8a\x5e\x16\xd8\x3d\xc0\x56\xa1\x57\xba\xd3\x3b\xc8\x65\xa9\x07\xe4\xba\xec\x49\x4a\x6d\x3c\x39\x86\x36\x6c\xbf\xb6\x84\x14\x9e\x6a\xca\x19\x9c\x86\x02\xa5\x37\xea\xa5\x28\x9d\x6d\x76\xe1\xe4\x44\x0a\xf1\xea\xad\xb9\x90\xca\xab\x28\x57\x9e\xf0\x56\x91\xc1\x70\xc6\x7d\x4b\xb6\x8c\x38\x9d\x9c\x8d\x71\xc6\xc1\x66\xaf\x94\xf3\x01\x1c\xda\x96\x12\xa8\xf6\x84\xc3\xaf\xdf\x07\x90\x29\x62\x84\x80\x8b\xb9\xd6\x4a\xf1\x56\x91\x23\xb4\xa9\x2e\x70\x1e\xa7\x39\x49\x79\x3c\x4e\xd6\x7a\x74\x9e\x0a\xb0\x45\x46\xb8\x51\xcb\x68\xc9\x4d\xdb\x24\x43\x74\xc2\xf7\x75\xf0\x04\xde\x86\x01\x7a\x28\x47\x81\x46\x31\xab\xbc\x1a\x29\xb8\x22\x94\xda\x79\x46\x90\xa9\x87\x2d\x87\xa3\x91\x0d\x6f\x9a\x2b\x28\x03\xfa\xd9\xa9\x1a\xbe\x52\x8e\x42\x87\x66\x8f\x82\x5a\xeb\x35\xef\x44\x5f\x7d\xf9\x44\x3c\x13\x8c\x99\xdf\xa8\x4f\xe9\x23\x6e\xf0\x23\x0b\x0a\x04\x89\x38\x9d\x6e\xc5\xff\x88\x26\x50\x1e\x38\xa2\xff\x62\xbc\xe0\x3b\x06\xcb\x06\x37\x46\xe1\x2f\x37\xea\x7d\xd4\xa6\x93\x70\x71\x3c\xb9\xba\x68\xfd\x19\x47\x57\xf8\x93\xa3\x4b\xe9\xd1\xd1\x2c\x10\xcf\x23\x6e\xb6\x6f\xb4\xd9\x6b\x13\x43\xed\x5e\x9c\x2e\xa4\x10\x8f\xd1\x9b\x23\x1c\x4a\x57\x67\xbb\x16\x59\xbf\x7d\x83\x3c\x04\x68\x17\xd5\x39\x44\x4c\xe9\xd2\x88\xf3\x20\xa8\x14\x42\x99\x62\xc8\x3a\xce\x4f\xa2\x4f\x55\xba\x6b\x1f\x7e\x8f\xa7\x15\x26\x69\x67\xdd\xb2\x16\x23\x7d\x36\x85\x74\xb0\x28\xbc\x41\x55\x33\xb9\xa7\x7f\x05\x8f\xc2\xd9\x16\xb9\xad\xbb\xc6\xcc\x2c\xa7\x5d\xf3\x4c\xbd\x6d\x9c\xc4\x3d\xc9\x3f\x03\x00\x09\x53\x1a\x8c\x7c\x06\x00\x00")
+
+func migrations0003_entries_backingsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0003_entries_backingsSql,
+		"migrations/0003_entries_backings.sql",
+	)
+}
+
+func migrations0003_entries_backingsSql() (*asset, error) {
+	bytes, err := migrations0003_entries_backingsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0003_entries_backings.sql", size: 1660, mode: os.FileMode(420), modTime: time.Unix(1792211015, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0004_entry_contributionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6c\x8e\xcd\x6e\xc2\x30\x10\x84\xcf\xf6\x53\xcc\x31\xa8\x4d\xc4\x1d\xf1\x18\x3d\x57\xeb\x78\x09\x56\x1d\xdb\xb2\xc7\x45\xbc\x7d\x45\xa8\xe8\x8f\x38\xec\x61\x47\xf3\xf3\x8d\x23\x5e\xd6\xb0\x54\xa1\xe2\xad\x58\x89\xd4\x0a\x8a\x8b\x0a\xe6\x5e\x93\xac\x9a\xf8\xae\x89\x35\x68\x83\x78\x8f\x39\xc7\xbe\x26\xcc\xf9\xa6\xb9\xce\x90\x13\x5c\x58\x42\x22\x52\x26\x52\x8f\x11\x5e\x4f\xd2\x23\xb1\x3f\x58\x3b\x8e\xb8\x9c\x85\xfa\x79\x2b\x3e\x2b\x9c\xcc\x1f\x5a\x1b\x7c\xf0\x5b\xa0\xc8\x15\x17\x69\x28\x12\x3c\xdc\x75\xf3\x94\x28\x57\xad\xb6\x17\x2f\x7c\x0a\x42\xb5\xa6\x29\xff\x52\x1c\xb1\x54\x15\x6a\xe3\xc0\xc9\x6b\xc9\x2d\x10\x23\xe6\x2c\x51\xdb\xac\xc3\x60\x8d\x69\x1a\x75\x26\x5a\x5f\x07\x37\xc9\x9a\x7b\xe2\xce\x1a\x73\xaa\x79\xdd\xc8\x42\x5a\x1a\x9c\x35\xe6\x72\xd6\xaa\x70\xd3\xaf\xf1\xe0\x71\x04\xf5\x9f\x24\xc9\xc3\x4d\x77\xe2\x87\xe5\xf1\xee\x5e\xb1\xdf\xce\xde\x37\x7e\xb2\x0d\xb4\xdf\x2b\x9c\x9e\x56\x1f\xec\xd7\x00\xed\xd9\xa1\x34\x9e\x01\x00\x00")
+
+func migrations0004_entry_contributionSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0004_entry_contributionSql,
+		"migrations/0004_entry_contribution.sql",
+	)
+}
+
+func migrations0004_entry_contributionSql() (*asset, error) {
+	bytes, err := migrations0004_entry_contributionSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0004_entry_contribution.sql", size: 414, mode: os.FileMode(420), modTime: time.Unix(1792211147, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0005_roundingSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x94\xcc\xb1\x0d\x02\x31\x0c\x05\xd0\x3e\x53\xfc\x2e\x05\xca\x04\x37\x07\x03\x18\xfc\x89\x22\x39\xce\xc9\x38\x12\x6c\x4f\x4d\x79\x0b\xbc\xd6\x70\x9b\xa3\x87\x24\x71\x3f\x8b\x58\x32\x90\xf2\x30\x22\xd7\x0e\x97\x49\xcf\x37\x44\x15\xcf\x65\x7b\x3a\x62\x6d\xd7\xe1\x1d\xc9\x4f\xc2\x57\xc2\xb7\x19\x94\x2f\xd9\x96\xa8\xc1\x29\xc3\x95\xd1\x72\xb5\xd3\xe4\xcb\xa8\x47\xf9\x93\x8d\xda\x19\x57\xd0\x7a\x94\xdf\x00\x1b\x0d\x02\x09\xaa\x00\x00\x00")
+
+func migrations0005_roundingSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0005_roundingSql,
+		"migrations/0005_rounding.sql",
+	)
+}
+
+func migrations0005_roundingSql() (*asset, error) {
+	bytes, err := migrations0005_roundingSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0005_rounding.sql", size: 170, mode: os.FileMode(420), modTime: time.Unix(1792211234, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0006_tournament_stateSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x54\xcd\xb1\x8d\xc3\x30\x0c\x05\xd0\xde\x53\xfc\xce\xc5\x41\x13\xdc\x1c\xa9\x03\xc6\xa2\x1d\x01\x34\x69\x50\x5f\x70\xb2\x7d\xe0\x2e\x59\xe0\xbd\x52\xf0\xb7\xb7\x2d\x85\x8a\xdb\x31\x95\x02\xc6\x48\x97\x5d\x9d\x1d\xe2\x1e\xc3\x17\xad\xe8\x81\x55\x12\xa7\xa6\x22\x0e\x75\xac\x91\x48\xdd\x5a\x67\x0a\x5b\x38\xb2\x6d\x4f\x42\x4e\x79\x4f\x62\xd4\x04\xe5\x61\xfa\xcb\xd5\x8a\x25\x6c\xec\x8e\xce\x6b\xa4\xbe\x08\x0f\xc2\x87\x19\xaa\xae\x32\x8c\x98\xbf\xdd\xfb\xb5\xcd\xff\xd3\x67\x00\x9a\x78\x10\x89\xa9\x00\x00\x00")
+
+func migrations0006_tournament_stateSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0006_tournament_stateSql,
+		"migrations/0006_tournament_state.sql",
+	)
+}
+
+func migrations0006_tournament_stateSql() (*asset, error) {
+	bytes, err := migrations0006_tournament_stateSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0006_tournament_state.sql", size: 169, mode: os.FileMode(420), modTime: time.Unix(1792211301, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0007_tournament_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8c\xd0\xc1\x6a\xc3\x30\x10\x04\xd0\xbb\xbf\x62\x8e\x09\xad\xa1\xf7\x7c\x47\xcf\x66\x6d\x6d\xec\x25\xf2\x4a\xc8\x23\x9c\xe4\xeb\x4b\x1b\x4a\xe2\x96\x96\x5c\xb5\xda\xc7\xcc\xb6\x2d\x5e\x66\x1b\x8b\x50\xf1\x9e\x9b\xb6\x05\x53\x2d\x2e\xb3\x3a\x17\x48\x51\x9c\x34\x13\xc9\x07\x05\x27\xbd\x7c\x3d\x1d\xcd\x6d\x99\x34\xbc\x42\x62\xf2\x11\xab\x71\xc2\x3a\x09\x6f\x5f\xb2\x58\x68\x24\x52\x0b\x28\x7d\xd4\x2d\x19\x02\x86\x14\xeb\xec\x18\x8a\x0a\x35\x74\x9f\x7b\x36\xeb\x42\x99\x33\xaf\xf0\x44\x78\x8d\x11\x41\x8f\x52\x23\xe1\x69\xdd\xed\x0f\xcf\x88\xdf\xc1\x7e\x90\x7f\xed\x76\xea\x2c\xa6\x1b\x22\x17\xbb\x2a\x7a\x1b\xcd\xf9\x3b\xca\xdb\x96\xea\x65\x38\x99\x8f\x5b\x40\x2e\xa9\xf2\x3f\xa1\xb9\x15\x87\x79\xd0\xf3\x63\x95\xee\x7e\x91\xce\xc2\x19\xc9\x1f\xa7\xd8\xdd\xc7\xfb\x43\xf3\x31\x00\xfa\x97\x78\xa0\xba\x01\x00\x00")
+
+func migrations0007_tournament_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0007_tournament_historySql,
+		"migrations/0007_tournament_history.sql",
+	)
+}
+
+func migrations0007_tournament_historySql() (*asset, error) {
+	bytes, err := migrations0007_tournament_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0007_tournament_history.sql", size: 442, mode: os.FileMode(420), modTime: time.Unix(1792212098, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0008_overlaySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x04\xc0\xc1\x09\x82\x31\x0c\x05\xe0\x7b\xa7\x78\x77\x29\x78\x77\x0e\x07\x48\x6d\x2c\x85\x34\x91\xf8\x22\xb8\xfd\xff\xf5\x8e\xdb\xd9\x2b\x85\x8a\xe7\xa7\x89\x51\x13\x94\x61\x0a\x46\xa5\xcb\x51\xe7\x17\x32\x27\x5e\x61\x75\x1c\xf1\xd3\x34\xf9\x63\xec\xb5\x9d\xf0\x20\xbc\xcc\x30\xf5\x2d\x65\xc4\xfd\xd1\xae\x01\x00\x83\x18\xe8\x8e\x55\x00\x00\x00")
+
+func migrations0008_overlaySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0008_overlaySql,
+		"migrations/0008_overlay.sql",
+	)
+}
+
+func migrations0008_overlaySql() (*asset, error) {
+	bytes, err := migrations0008_overlaySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0008_overlay.sql", size: 85, mode: os.FileMode(420), modTime: time.Unix(1792212190, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0009_payout_structureSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x04\xc0\xc1\x0d\x83\x30\x0c\x05\xd0\x7b\xa6\xf8\xb7\x1c\xaa\x4c\xd0\x39\x3a\x80\x4b\x0c\x42\x72\xec\x28\x7c\x4b\xb0\x3d\xaf\x35\x7c\xc6\x79\x2c\xa1\xe2\x37\x8b\x18\x75\x81\xf2\x37\x05\x23\x97\xcb\x50\xe7\x05\xe9\x1d\x5b\x58\x0e\xc7\x94\x27\x92\xa0\xde\x84\x07\xe1\x69\x86\xae\xbb\xa4\x11\xb5\x7e\xcb\x3b\x00\x80\x33\x3b\xf1\x53\x00\x00\x00")
+
+func migrations0009_payout_structureSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0009_payout_structureSql,
+		"migrations/0009_payout_structure.sql",
+	)
+}
+
+func migrations0009_payout_structureSql() (*asset, error) {
+	bytes, err := migrations0009_payout_structureSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0009_payout_structure.sql", size: 83, mode: os.FileMode(420), modTime: time.Unix(1792212250, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0010_entry_rankSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x04\xc0\x31\x0a\x42\x31\x10\x04\xd0\xfe\x9f\x62\x7a\xf9\x60\xef\x39\xac\x65\x35\x63\x08\x6e\x26\xb2\x4e\xee\xef\x3b\x4f\x5c\xe6\xe8\x15\x26\xee\xdf\x23\xd2\x2c\x38\x9e\x49\x78\xed\x52\x4c\xca\x0f\xca\x35\xf8\x43\xb4\x86\xd7\xca\x3d\x85\x0a\x7d\x30\x64\x76\x16\xb4\x0c\xed\x4c\x34\xbe\x63\xa7\x71\xbd\x1d\xff\x01\x00\xde\x9a\x0a\x9d\x5a\x00\x00\x00")
+
+func migrations0010_entry_rankSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0010_entry_rankSql,
+		"migrations/0010_entry_rank.sql",
+	)
+}
+
+func migrations0010_entry_rankSql() (*asset, error) {
+	bytes, err := migrations0010_entry_rankSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0010_entry_rank.sql", size: 90, mode: os.FileMode(420), modTime: time.Unix(1792212312, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0011_rakeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x94\xce\x41\x0a\x02\x31\x0c\x85\xe1\xbd\xa7\x78\x7b\x19\x70\xef\x39\x5c\x97\xd4\xc6\x21\x98\xa6\x43\xfa\x7a\x7f\xf1\x06\x33\xeb\x1f\x3e\xfe\x6d\xc3\xbd\xdb\x9e\x42\xc5\xeb\xb8\x89\x53\x13\x94\xea\x0a\x8e\x95\x21\x5d\x83\x13\xd2\x1a\xde\xc3\x57\x0f\xa4\x7c\x15\xd5\x76\x0b\x22\x06\x11\xcb\x1d\x4d\x3f\xb2\x9c\x78\x3c\xcf\x12\xa5\xca\xb4\x59\x8e\x61\xff\x78\xd9\x2b\x1a\x4c\xd3\x4b\x67\xbf\x01\x00\x1b\x8c\x81\x68\xec\x00\x00\x00")
+
+func migrations0011_rakeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0011_rakeSql,
+		"migrations/0011_rake.sql",
+	)
+}
+
+func migrations0011_rakeSql() (*asset, error) {
+	bytes, err := migrations0011_rakeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0011_rake.sql", size: 236, mode: os.FileMode(420), modTime: time.Unix(1792212346, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0012_guaranteeSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x04\xc0\x41\x0e\x42\x21\x0c\x04\xd0\xfd\x3f\xc5\xec\x0d\x89\x7b\xcf\xe1\x01\x06\xa9\x84\xa4\x14\x53\xa7\xf7\xff\xaf\x35\x3c\xf6\x9a\x49\x19\xde\xbf\x8b\x2e\x4b\x88\xdd\x0d\x3a\x95\xc1\x6d\xa1\x3f\x38\x06\x3e\xc7\x6b\x07\x66\x31\x19\x32\x43\x5f\x73\x85\x10\x47\x88\x72\xc7\xb0\x2f\xcb\x85\xe7\xeb\xba\x07\x00\xfb\xae\x6a\x48\x57\x00\x00\x00")
+
+func migrations0012_guaranteeSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0012_guaranteeSql,
+		"migrations/0012_guarantee.sql",
+	)
+}
+
+func migrations0012_guaranteeSql() (*asset, error) {
+	bytes, err := migrations0012_guaranteeSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0012_guarantee.sql", size: 87, mode: os.FileMode(420), modTime: time.Unix(1792212411, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0013_registration_limitsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xac\xcf\x31\x6a\x04\x31\x0c\x85\xe1\x3e\xa7\x50\x1f\x0c\xe9\x73\x8e\xd4\x46\x33\x56\x8c\x40\x96\x07\xe9\x19\x42\x4e\xbf\x6c\xb7\x30\xcd\xec\xb2\xed\x2b\x3e\xde\x5f\x0a\x7d\x0e\xed\xc1\x10\xfa\x39\x3e\xd8\x20\x41\xe0\xcd\x84\x30\x57\x38\x0f\x71\x24\x71\x6b\xb4\x4f\x5b\xc3\x69\xf0\x5f\x15\x47\xf0\x7d\x57\x87\x74\x09\xf2\x09\xf2\x65\x46\x4d\x7e\x79\x19\xe8\xeb\xfb\x92\xa5\xfe\x36\x2b\xa4\x6b\x22\x18\x3a\xbd\xce\x43\x3c\x2b\x83\xa0\x43\x12\x3c\x0e\xfc\x3f\xaf\xec\x36\x53\xce\x4c\x29\xa4\x4e\x29\xfb\xf4\x96\x57\x50\x63\x48\x7d\x94\x69\xd3\xae\x8e\x57\x53\x13\x1c\x90\x76\x3a\x76\x1b\x00\x2f\xcd\x1d\x2a\xcd\x01\x00\x00")
+
+func migrations0013_registration_limitsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0013_registration_limitsSql,
+		"migrations/0013_registration_limits.sql",
+	)
+}
+
+func migrations0013_registration_limitsSql() (*asset, error) {
+	bytes, err := migrations0013_registration_limitsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0013_registration_limits.sql", size: 461, mode: os.FileMode(420), modTime: time.Unix(1792212453, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0014_reentriesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9c\x92\x4d\x6e\xeb\x30\x0c\x84\xd7\x2f\xa7\x98\xe5\x0b\x1a\x03\xed\x3a\xe7\xe8\x3a\xa0\xa5\xb1\x2b\x44\xa2\x0c\x5a\x5e\xe8\xf6\x45\x7e\xdb\x24\x4d\x13\x77\x67\x58\xe4\x47\xce\x0c\x9b\x06\x2f\x29\xf4\x26\x85\x78\x1f\x16\x4d\x03\xc1\x10\xa5\xd2\x90\xa4\x82\x5a\x68\x10\x94\x3c\x99\x4a\xa2\x16\xa4\x6c\x44\xf9\x10\x45\x56\xc7\xd5\xae\xc4\x02\x47\x88\x11\x3a\xa5\x96\x46\x8f\x81\x76\xc4\x2c\x24\xee\x10\x45\xda\x48\xb4\xe2\xb6\x41\xfb\x11\xde\xf2\x00\x97\x75\x2c\x26\x41\xcb\xf9\x61\xf3\x35\x68\x13\xfc\xe6\x80\xd8\x7d\x75\x5b\xd6\xf5\x4c\xd6\x70\xd3\xf3\x8d\x7e\x5a\xfb\xba\xfb\xb6\xe4\xc8\x79\x04\x12\xef\xe1\x72\x9c\x92\xee\x2d\xa9\x08\x5a\xd8\xd3\xa0\xb9\x40\xa7\x18\xe1\xd9\xc9\x14\x0b\xde\xd6\x33\x58\xc6\x76\xaa\xe3\x7d\xd8\xeb\x53\xb0\xc1\x42\x12\xab\xd8\xb2\xe2\xff\x85\xc7\x2b\x9c\x4d\x3e\x64\x59\x97\x57\x5a\xcf\x46\xff\x59\xe1\x05\x61\xce\x2a\xab\x7d\xeb\xfe\xcf\xf2\x17\x64\x97\x8d\xa1\xd7\xe7\xd4\x2d\xfe\x19\x3b\x1a\xd5\x71\xfc\xc9\xae\x87\x00\x64\x85\x67\x64\x21\x9c\x8c\x4e\x3c\xef\x1e\xc7\x55\x92\xa7\x11\xf3\xc3\x9c\x7b\x12\x9f\x03\x00\x9f\x11\x02\x91\xd7\x03\x00\x00")
+
+func migrations0014_reentriesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0014_reentriesSql,
+		"migrations/0014_reentries.sql",
+	)
+}
+
+func migrations0014_reentriesSql() (*asset, error) {
+	bytes, err := migrations0014_reentriesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0014_reentries.sql", size: 983, mode: os.FileMode(420), modTime: time.Unix(1792212581, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0015_player_profilesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x94\xce\xcb\x09\x02\x31\x18\x45\xe1\xbd\x55\xdc\xdd\x28\x32\x15\x58\x87\x6b\xb9\x33\xf9\x95\x91\xbc\x48\x6e\x7c\x62\xef\x16\xa0\x12\x2c\xe0\x7c\x9c\x71\xc4\x36\x2c\xa7\x42\x19\xf6\x79\x45\x2f\x2b\x10\x27\x6f\xc8\x9e\x77\x2b\x15\x74\x0e\x73\xf2\x2d\x44\x44\x06\x83\xec\x26\xc4\x24\xc4\xe6\x3d\x9c\x1d\xd9\xbc\x30\x0c\xbb\x5e\x1d\x4c\x74\x14\x71\xae\x29\x4e\x5f\x88\xe7\xab\x8f\x54\x51\xad\xfe\x9a\xe0\xac\xe5\x62\x7d\x65\x2e\x46\x99\x3b\x50\xd0\x12\xac\x8a\x21\xeb\xf1\x09\xc6\x74\x5d\x6f\xba\x5a\xcb\xee\x1f\xed\x3d\x00\x36\x7d\x83\x5e\x74\x01\x00\x00")
+
+func migrations0015_player_profilesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0015_player_profilesSql,
+		"migrations/0015_player_profiles.sql",
+	)
+}
+
+func migrations0015_player_profilesSql() (*asset, error) {
+	bytes, err := migrations0015_player_profilesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0015_player_profiles.sql", size: 372, mode: os.FileMode(420), modTime: time.Unix(1792212679, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0016_player_statusSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xa4\xcc\xc1\x0d\xc2\x30\x0c\x05\xd0\x7b\xa7\xf8\xb7\x1e\x50\x27\xe8\x1c\x9c\x91\x4b\x0c\x8a\xe4\x38\x51\xfc\x2d\x51\xa6\x67\x05\xa4\x2e\xf0\xb6\x0d\xb7\x56\xdf\x53\xa8\xb8\x8f\x45\x8c\x3a\x41\x39\x4c\x31\x4c\x4e\x9d\x01\x29\x05\xcf\x6e\xd9\x1c\x41\x61\xc6\x63\xaa\x44\x77\x50\x3f\x84\x77\xc2\xd3\x0c\x45\x5f\x92\x46\xac\xeb\xfe\x27\x73\x9c\x97\x89\x74\x56\x03\x6b\xd3\xa0\xb4\xc1\xef\xbe\xfc\x06\x00\x24\xf0\xf9\xd8\xd2\x00\x00\x00")
+
+func migrations0016_player_statusSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0016_player_statusSql,
+		"migrations/0016_player_status.sql",
+	)
+}
+
+func migrations0016_player_statusSql() (*asset, error) {
+	bytes, err := migrations0016_player_statusSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0016_player_status.sql", size: 210, mode: os.FileMode(420), modTime: time.Unix(1792214235, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0017_transfersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x94\x90\x41\x6e\x84\x30\x0c\x45\xd7\xe4\x14\x5e\x82\xca\x48\xdd\x23\xf5\x16\x5d\x23\x4f\x62\xa6\xd6\x24\x26\x72\x8c\x0a\x3d\x7d\x45\xa7\x03\xb4\x55\x17\xb3\x8b\xf4\xff\x7f\x8a\xdf\xe9\x04\x4f\x89\x2f\x8a\x46\xf0\x9a\x9d\x57\x5a\x5f\x86\xe7\x48\x60\x8a\x52\x06\xd2\x02\xb5\xab\x38\x80\xd1\x6c\x90\x95\x13\xea\x02\x57\x5a\x5a\x57\xe5\x88\x0b\x69\x7f\x0f\x65\x34\x90\x29\x46\x50\x1a\x48\x49\x3c\x15\xb8\x55\x0a\xd4\x1c\x9a\xd6\x55\x4a\x9e\x33\x93\xd8\x23\x23\x4c\xe3\x24\x06\x67\xbe\xb0\x1c\x06\xfe\x8d\xfc\x15\xea\xef\xf4\x05\x9e\xd7\x6e\x31\xb4\xa9\xfc\x44\xb7\xae\xba\x5d\x16\x7a\x34\x30\x4e\x54\x0c\x53\xb6\x8f\x9d\x15\x68\xc0\x29\xae\x93\xf7\xfa\x0b\x43\x66\xf1\x4f\xdf\x35\x9d\xbb\x4b\x62\x09\x34\xef\x92\xfa\xcd\xc5\x0c\xa3\x1c\xe5\x6d\x41\x0b\xfb\x2f\x9a\xee\x3f\xce\xd1\xd0\x6f\xd4\x31\x6b\x3a\xf7\x39\x00\xa2\x2c\xab\x2c\xbe\x01\x00\x00")
+
+func migrations0017_transfersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0017_transfersSql,
+		"migrations/0017_transfers.sql",
+	)
+}
+
+func migrations0017_transfersSql() (*asset, error) {
+	bytes, err := migrations0017_transfersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0017_transfers.sql", size: 446, mode: os.FileMode(420), modTime: time.Unix(1792216410, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0018_holdsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x74\x90\xc1\x6a\xc3\x30\x10\x44\xcf\xd6\x57\xec\x2d\x36\x75\x20\xf7\xd0\xfe\x45\xcf\x66\x23\x4d\xe2\x25\xb2\x2c\xa4\x55\x63\xf7\xeb\x8b\x71\x1c\x52\x42\x6e\x82\x79\x62\x66\xdf\x7e\x4f\x1f\x83\x5c\x12\x2b\xe8\x3b\x1a\x9b\xb0\xbc\x94\x4f\x1e\xd4\x8f\xde\x65\xaa\x4d\x25\x8e\x14\x93\x52\x4c\x32\x70\x9a\xe9\x8a\xb9\x35\x55\xf4\x3c\x23\x75\x5b\x18\x46\xa5\x50\xbc\xa7\x84\x33\x12\x82\x45\xa6\x15\xc9\x54\x8b\x6b\x5a\x53\xf1\x30\x96\xa0\x74\x92\x8b\x84\xa7\x0f\xb6\x87\xbd\x52\x7d\x4f\xbf\xe8\xb0\xb0\x96\xa3\x96\x04\xf7\x42\x3b\x9c\xb9\x78\xa5\x43\x6b\xaa\xac\xac\x25\xff\xef\x6f\x4d\x85\x29\x4a\x42\xee\x58\x49\x65\x40\x56\x1e\xa2\xfe\x3e\x13\xeb\x9d\xee\x1d\xf1\x28\x09\xe3\xad\x5e\xd6\x64\xa8\xfa\x17\xde\x34\x47\xb3\x29\x93\xe0\x30\xad\xca\x3a\xb6\x2a\x3f\xe8\xc4\x4d\x34\x86\x4d\xe3\x43\x57\x43\xb7\x1e\x09\x74\x1f\xff\x49\xbb\x95\xdf\x1d\xcd\xdf\x00\xca\x50\xd3\x77\x8e\x01\x00\x00")
+
+func migrations0018_holdsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0018_holdsSql,
+		"migrations/0018_holds.sql",
+	)
+}
+
+func migrations0018_holdsSql() (*asset, error) {
+	bytes, err := migrations0018_holdsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0018_holds.sql", size: 398, mode: os.FileMode(420), modTime: time.Unix(1792214479, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0019_offersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb4\x92\xcd\x6e\xab\x40\x0c\x46\xd7\xcc\x53\x78\x49\x74\x89\xc4\x5d\xdf\x9f\xb7\xe8\x3a\x72\x18\x13\x46\x30\x1e\xe4\xf1\xa8\xa1\x4f\x5f\x01\x65\x92\xb4\x11\x52\x17\xdd\x81\xe6\x7c\xb6\xe5\xe3\xe3\x11\x7e\x79\x77\x11\x54\x82\x97\xd1\x34\x42\xf3\x97\xe2\x79\x20\x08\x6d\x4b\x12\xa1\x34\x85\xb3\xa0\x74\x55\x18\xc5\x79\x94\x09\x7a\x9a\x2a\x53\x68\x48\xc2\xe8\x89\xf5\xb4\x01\x1c\x14\x38\x0d\x03\x08\xb5\x24\xc4\x0d\x45\xb8\x61\x11\x4a\x67\x0f\x95\x29\xc6\x01\x27\x92\xdd\xd4\x8a\xe4\x44\xec\x50\x08\xce\xee\xe2\xf8\x8e\x6f\x3a\x6a\x7a\x28\xd7\xc7\xff\x50\x03\xb2\x85\xf5\xef\xef\x3f\xf8\x5d\xd7\x75\x3d\x87\x3d\x4a\x9f\xc6\x2f\x69\x4b\x2d\xa6\x41\xa1\xae\x4c\x61\x09\xed\xe0\x98\x40\x9d\xa7\xa8\xe8\x47\x7d\xcb\xe4\xdc\x5f\x51\x53\x7c\x1c\xb7\x32\x05\xb1\xca\x04\x8e\x95\x2e\x24\xcf\x2b\xaf\x2b\xb5\x27\xd4\xa7\xb5\x33\xcb\xe1\xb5\x3c\x98\xc3\x1f\xb3\x49\x70\x6c\xe9\xfa\x21\xe1\xf4\xb0\xeb\x2b\x04\xce\x76\x1e\x5e\x2a\xb8\xb5\xbb\x2b\xb5\xfa\x1c\x93\x34\x1d\x46\x5a\x94\x2e\xf1\x5d\x03\x5b\x83\x55\xc0\x19\x9b\xfe\x27\x94\xcd\x24\xfa\x90\x58\x3f\xa3\xf3\x9d\x08\x79\x97\xfc\xae\xb9\x2e\x0c\x76\x77\xac\x19\xc8\x43\x7d\xcb\xc6\x32\x41\xbe\x78\x28\xb7\x9d\x55\x90\xb7\xb1\x18\x7b\x1f\x00\x66\x0b\x61\x16\x45\x03\x00\x00")
+
+func migrations0019_offersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0019_offersSql,
+		"migrations/0019_offers.sql",
+	)
+}
+
+func migrations0019_offersSql() (*asset, error) {
+	bytes, err := migrations0019_offersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0019_offers.sql", size: 837, mode: os.FileMode(420), modTime: time.Unix(1792214590, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations0020_backing_premiumsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x04\xc0\xc1\x0d\x04\x21\x08\x05\xd0\xbb\x55\xfc\xfb\xc6\x64\xef\x5b\xc7\x16\x80\xca\x18\x32\x80\xc6\x81\xfe\xe7\xd5\x8a\x8f\xc9\x3c\x14\x8c\xff\x2e\xa4\xc1\x07\x41\x4d\x19\x8d\xfa\x2d\x3e\x1f\xd0\x18\xe8\x4b\xd3\x1c\xfb\xb0\x49\x1a\x9a\x4c\xf1\x80\xaf\x80\xa7\x2a\x06\x5f\x94\x1a\xf8\xfe\xca\x3b\x00\x7b\x67\xbf\xa1\x52\x00\x00\x00")
+
+func migrations0020_backing_premiumsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations0020_backing_premiumsSql,
+		"migrations/0020_backing_premiums.sql",
+	)
+}
+
+func migrations0020_backing_premiumsSql() (*asset, error) {
+	bytes, err := migrations0020_backing_premiumsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/0020_backing_premiums.sql", size: 82, mode: os.FileMode(420), modTime: time.Unix(1792215448, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -141,6 +540,25 @@ func AssetNames() []string {
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
 	"migrations/0001_initial.sql": migrations0001_initialSql,
+	"migrations/0002_ledger.sql": migrations0002_ledgerSql,
+	"migrations/0003_entries_backings.sql": migrations0003_entries_backingsSql,
+	"migrations/0004_entry_contribution.sql": migrations0004_entry_contributionSql,
+	"migrations/0005_rounding.sql": migrations0005_roundingSql,
+	"migrations/0006_tournament_state.sql": migrations0006_tournament_stateSql,
+	"migrations/0007_tournament_history.sql": migrations0007_tournament_historySql,
+	"migrations/0008_overlay.sql": migrations0008_overlaySql,
+	"migrations/0009_payout_structure.sql": migrations0009_payout_structureSql,
+	"migrations/0010_entry_rank.sql": migrations0010_entry_rankSql,
+	"migrations/0011_rake.sql": migrations0011_rakeSql,
+	"migrations/0012_guarantee.sql": migrations0012_guaranteeSql,
+	"migrations/0013_registration_limits.sql": migrations0013_registration_limitsSql,
+	"migrations/0014_reentries.sql": migrations0014_reentriesSql,
+	"migrations/0015_player_profiles.sql": migrations0015_player_profilesSql,
+	"migrations/0016_player_status.sql": migrations0016_player_statusSql,
+	"migrations/0017_transfers.sql": migrations0017_transfersSql,
+	"migrations/0018_holds.sql": migrations0018_holdsSql,
+	"migrations/0019_offers.sql": migrations0019_offersSql,
+	"migrations/0020_backing_premiums.sql": migrations0020_backing_premiumsSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -186,6 +604,25 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"migrations": &bintree{nil, map[string]*bintree{
 		"0001_initial.sql": &bintree{migrations0001_initialSql, map[string]*bintree{}},
+		"0002_ledger.sql": &bintree{migrations0002_ledgerSql, map[string]*bintree{}},
+		"0003_entries_backings.sql": &bintree{migrations0003_entries_backingsSql, map[string]*bintree{}},
+		"0004_entry_contribution.sql": &bintree{migrations0004_entry_contributionSql, map[string]*bintree{}},
+		"0005_rounding.sql": &bintree{migrations0005_roundingSql, map[string]*bintree{}},
+		"0006_tournament_state.sql": &bintree{migrations0006_tournament_stateSql, map[string]*bintree{}},
+		"0007_tournament_history.sql": &bintree{migrations0007_tournament_historySql, map[string]*bintree{}},
+		"0008_overlay.sql": &bintree{migrations0008_overlaySql, map[string]*bintree{}},
+		"0009_payout_structure.sql": &bintree{migrations0009_payout_structureSql, map[string]*bintree{}},
+		"0010_entry_rank.sql": &bintree{migrations0010_entry_rankSql, map[string]*bintree{}},
+		"0011_rake.sql": &bintree{migrations0011_rakeSql, map[string]*bintree{}},
+		"0012_guarantee.sql": &bintree{migrations0012_guaranteeSql, map[string]*bintree{}},
+		"0013_registration_limits.sql": &bintree{migrations0013_registration_limitsSql, map[string]*bintree{}},
+		"0014_reentries.sql": &bintree{migrations0014_reentriesSql, map[string]*bintree{}},
+		"0015_player_profiles.sql": &bintree{migrations0015_player_profilesSql, map[string]*bintree{}},
+		"0016_player_status.sql": &bintree{migrations0016_player_statusSql, map[string]*bintree{}},
+		"0017_transfers.sql": &bintree{migrations0017_transfersSql, map[string]*bintree{}},
+		"0018_holds.sql": &bintree{migrations0018_holdsSql, map[string]*bintree{}},
+		"0019_offers.sql": &bintree{migrations0019_offersSql, map[string]*bintree{}},
+		"0020_backing_premiums.sql": &bintree{migrations0020_backing_premiumsSql, map[string]*bintree{}},
 	}},
 }}
 
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// The migrations are embedded by go-bindata, the embedded copies must be
+// regenerated whenever a migration is changed.
+func TestBindataMatchesMigrations(t *testing.T) {
+	files, err := filepath.Glob("migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(AssetNames()) != len(files) {
+		t.Errorf("%d migrations are embedded, %d are on disk", len(AssetNames()), len(files))
+	}
+
+	for _, name := range AssetNames() {
+		embedded, err := Asset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		onDisk, err := ioutil.ReadFile(filepath.FromSlash(name))
+		if err != nil {
+			t.Errorf("%s is embedded but not on disk: %v", name, err)
+			continue
+		}
+
+		if !bytes.Equal(embedded, onDisk) {
+			t.Errorf("%s is embedded out of date, run go-bindata -pkg storage migrations/...", name)
+		}
+	}
+}
@@ -0,0 +1,1087 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/xfreshx/lifland/types"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kvEngine is a transactional key-value engine. The memory and the Badger
+// backends are both a kvStore on top of their own engine.
+type kvEngine interface {
+	Begin() kvTxn
+	Reset() error
+	Close() error
+}
+
+// kvTxn is an engine transaction. Its writes are visible to its own reads and
+// are applied atomically on Commit.
+type kvTxn interface {
+	// Get returns ErrNotFound when the key does not exist.
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte) error
+	Delete(key string) error
+	// Scan calls fn for every key with the given prefix in key order.
+	Scan(prefix string, fn func(key string, value []byte) error) error
+	Commit() error
+	Discard()
+}
+
+// lockTimeout bounds the wait for a row lock. The engines have no deadlock
+// detection, so a waiting transaction gives up instead.
+const lockTimeout = 5 * time.Second
+
+var ErrLockTimeout = errors.New("timed out waiting for a row lock")
+
+// lockTable holds row locks of a kvStore. Rows are locked by ForUpdate reads
+// and by writes, and stay locked until the end of the transaction, the same
+// way Postgres does it.
+type lockTable struct {
+	mu   sync.Mutex
+	rows map[string]*rowLock
+}
+
+type rowLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func (l *lockTable) lock(key string) error {
+	l.mu.Lock()
+	r, ok := l.rows[key]
+	if !ok {
+		r = &rowLock{ch: make(chan struct{}, 1)}
+		l.rows[key] = r
+	}
+	r.refs++
+	l.mu.Unlock()
+
+	select {
+	case r.ch <- struct{}{}:
+		return nil
+	case <-time.After(lockTimeout):
+		l.release(key, r)
+		return ErrLockTimeout
+	}
+}
+
+func (l *lockTable) unlock(key string) {
+	l.mu.Lock()
+	r := l.rows[key]
+	l.mu.Unlock()
+
+	<-r.ch
+	l.release(key, r)
+}
+
+func (l *lockTable) release(key string, r *rowLock) {
+	l.mu.Lock()
+	r.refs--
+	if r.refs == 0 {
+		delete(l.rows, key)
+	}
+	l.mu.Unlock()
+}
+
+type kvStore struct {
+	engine kvEngine
+	locks  *lockTable
+}
+
+type kvTx struct {
+	store *kvStore
+	txn   kvTxn
+	held  map[string]bool
+	done  bool
+}
+
+func newKVStore(engine kvEngine) *kvStore {
+	return &kvStore{
+		engine: engine,
+		locks:  &lockTable{rows: make(map[string]*rowLock)},
+	}
+}
+
+func (s *kvStore) Begin() (Tx, error) {
+	return s.begin(), nil
+}
+
+func (s *kvStore) begin() *kvTx {
+	return &kvTx{store: s, txn: s.engine.Begin(), held: make(map[string]bool)}
+}
+
+// autocommit runs fn in its own transaction, the way a single statement
+// runs outside of a transaction block in Postgres.
+func (s *kvStore) autocommit(fn func(tx *kvTx) error) error {
+	tx := s.begin()
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *kvStore) Reset() error {
+	return s.engine.Reset()
+}
+
+func (s *kvStore) Close() {
+	_ = s.engine.Close()
+}
+
+func (s *kvStore) CreatePlayer(p *types.Player) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.CreatePlayer(p) })
+}
+
+func (s *kvStore) SetPlayer(p *types.Player) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.SetPlayer(p) })
+}
+
+func (s *kvStore) GetPlayer(id string) (p *types.Player, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		p, err = tx.GetPlayer(id)
+		return err
+	})
+	return p, err
+}
+
+func (s *kvStore) GetPlayersForUpdate(ids []string) (pp []*types.Player, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		pp, err = tx.GetPlayersForUpdate(ids)
+		return err
+	})
+	return pp, err
+}
+
+func (s *kvStore) UpdatePlayer(p *types.Player) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdatePlayer(p) })
+}
+
+func (s *kvStore) UpdateProfile(p *types.Player) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateProfile(p) })
+}
+
+func (s *kvStore) SetTournament(t *types.Tournament) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.SetTournament(t) })
+}
+
+func (s *kvStore) GetTournament(id string) (t *types.Tournament, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		t, err = tx.GetTournament(id)
+		return err
+	})
+	return t, err
+}
+
+func (s *kvStore) GetTournamentForUpdate(id string) (t *types.Tournament, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		t, err = tx.GetTournamentForUpdate(id)
+		return err
+	})
+	return t, err
+}
+
+func (s *kvStore) UpdateTournament(t *types.Tournament) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateTournament(t) })
+}
+
+func (s *kvStore) ListTournaments(f types.TournamentFilter) (tt []*types.Tournament, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		tt, err = tx.ListTournaments(f)
+		return err
+	})
+	return tt, err
+}
+
+func (s *kvStore) AddTournamentEntry(e *types.Entry) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddTournamentEntry(e) })
+}
+
+func (s *kvStore) UpdateTournamentEntry(e *types.Entry) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateTournamentEntry(e) })
+}
+
+func (s *kvStore) AddBacking(b *types.Backing) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddBacking(b) })
+}
+
+func (s *kvStore) AddTransfer(tr *types.Transfer) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddTransfer(tr) })
+}
+
+func (s *kvStore) GetTransfer(id string) (tr *types.Transfer, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		tr, err = tx.GetTransfer(id)
+		return err
+	})
+	return tr, err
+}
+
+func (s *kvStore) GetTransferForUpdate(id string) (tr *types.Transfer, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		tr, err = tx.GetTransferForUpdate(id)
+		return err
+	})
+	return tr, err
+}
+
+func (s *kvStore) UpdateTransfer(tr *types.Transfer) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateTransfer(tr) })
+}
+
+func (s *kvStore) TransferredSince(playerId string, since time.Time) (sum uint64, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		sum, err = tx.TransferredSince(playerId, since)
+		return err
+	})
+	return sum, err
+}
+
+func (s *kvStore) AddHold(h *types.Hold) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddHold(h) })
+}
+
+func (s *kvStore) GetHold(id string) (h *types.Hold, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		h, err = tx.GetHold(id)
+		return err
+	})
+	return h, err
+}
+
+func (s *kvStore) GetHoldForUpdate(id string) (h *types.Hold, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		h, err = tx.GetHoldForUpdate(id)
+		return err
+	})
+	return h, err
+}
+
+func (s *kvStore) UpdateHold(h *types.Hold) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateHold(h) })
+}
+
+func (s *kvStore) AddOffer(o *types.Offer) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddOffer(o) })
+}
+
+func (s *kvStore) GetOffer(id string) (o *types.Offer, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		o, err = tx.GetOffer(id)
+		return err
+	})
+	return o, err
+}
+
+func (s *kvStore) GetOfferForUpdate(id string) (o *types.Offer, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		o, err = tx.GetOfferForUpdate(id)
+		return err
+	})
+	return o, err
+}
+
+func (s *kvStore) UpdateOffer(o *types.Offer) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.UpdateOffer(o) })
+}
+
+func (s *kvStore) ListOffers(tournamentId string) (oo []*types.Offer, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		oo, err = tx.ListOffers(tournamentId)
+		return err
+	})
+	return oo, err
+}
+
+func (s *kvStore) AddPurchase(p *types.Purchase) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.AddPurchase(p) })
+}
+
+func (s *kvStore) InsertLedgerEntry(e *types.LedgerEntry) error {
+	return s.autocommit(func(tx *kvTx) error { return tx.InsertLedgerEntry(e) })
+}
+
+func (s *kvStore) ListLedgerEntries(f types.LedgerFilter) (ee []*types.LedgerEntry, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		ee, err = tx.ListLedgerEntries(f)
+		return err
+	})
+	return ee, err
+}
+
+func (s *kvStore) GetAccountBalance(account string) (balance int64, err error) {
+	err = s.autocommit(func(tx *kvTx) error {
+		balance, err = tx.GetAccountBalance(account)
+		return err
+	})
+	return balance, err
+}
+
+func (t *kvTx) Commit() error {
+	if t.done {
+		return errors.New("transaction has already been committed or rolled back")
+	}
+
+	err := t.txn.Commit()
+	t.finish()
+
+	return err
+}
+
+func (t *kvTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+
+	t.txn.Discard()
+	t.finish()
+
+	return nil
+}
+
+func (t *kvTx) finish() {
+	t.done = true
+	for key := range t.held {
+		t.store.locks.unlock(key)
+	}
+	t.held = nil
+}
+
+func (t *kvTx) lock(key string) error {
+	if t.held[key] {
+		return nil
+	}
+
+	if err := t.store.locks.lock(key); err != nil {
+		return err
+	}
+	t.held[key] = true
+
+	return nil
+}
+
+func (t *kvTx) get(key string, v interface{}) error {
+	b, err := t.txn.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func (t *kvTx) getForUpdate(key string, v interface{}) error {
+	if err := t.lock(key); err != nil {
+		return err
+	}
+
+	return t.get(key, v)
+}
+
+func (t *kvTx) put(key string, v interface{}) error {
+	if err := t.lock(key); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return t.txn.Set(key, b)
+}
+
+func (t *kvTx) delete(key string) error {
+	if err := t.lock(key); err != nil {
+		return err
+	}
+
+	return t.txn.Delete(key)
+}
+
+// nextId increments a stored sequence. The sequence stays locked until the
+// end of the transaction, so ids are handed out in commit order.
+func (t *kvTx) nextId(name string) (uint64, error) {
+	key := "seq/" + name
+
+	var id uint64
+	err := t.getForUpdate(key, &id)
+	if err != nil && err != ErrNotFound {
+		return 0, err
+	}
+	id++
+
+	return id, t.put(key, id)
+}
+
+// seqKey keeps keys of sequential records in id order.
+func seqKey(prefix string, id uint64) string {
+	return fmt.Sprintf("%s%020d", prefix, id)
+}
+
+// kvKey joins escaped parts with "/", so that the keys of one id are never
+// a prefix of the keys of another.
+func kvKey(parts ...string) string {
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func playerKey(id string) string {
+	return "player/" + id
+}
+
+func tournamentKey(id string) string {
+	return "tournament/" + id
+}
+
+func transferKey(id string) string {
+	return kvKey("transfer", id)
+}
+
+func holdKey(id string) string {
+	return kvKey("hold", id)
+}
+
+// playerHoldPrefix indexes the active holds of a player.
+func playerHoldPrefix(playerId string) string {
+	return kvKey("holds", playerId) + "/"
+}
+
+func offerKey(id string) string {
+	return kvKey("offer", id)
+}
+
+func purchasePrefix(offerId string) string {
+	return kvKey("purchase", offerId) + "/"
+}
+
+func purchaseKey(p *types.Purchase) string {
+	return kvKey("purchase", p.OfferId, p.BackerId)
+}
+
+func entryPrefix(tournamentId string) string {
+	return kvKey("entry", tournamentId) + "/"
+}
+
+// entryKey is the key of an entry. The first entry of a player keeps the
+// key it had before players could enter more than once.
+func entryKey(e *types.Entry) string {
+	if e.Number > 1 {
+		return kvKey("entry", e.TournamentId, e.PlayerId, strconv.Itoa(e.Number))
+	}
+
+	return kvKey("entry", e.TournamentId, e.PlayerId)
+}
+
+func backingPrefix(tournamentId string) string {
+	return kvKey("backing", tournamentId) + "/"
+}
+
+func backingKey(b *types.Backing) string {
+	if b.Entry > 1 {
+		return kvKey("backing", b.TournamentId, b.PlayerId, b.BackerId, strconv.Itoa(b.Entry))
+	}
+
+	return kvKey("backing", b.TournamentId, b.PlayerId, b.BackerId)
+}
+
+// scan decodes every value under the prefix with decode.
+func (t *kvTx) scan(prefix string, decode func(value []byte) error) error {
+	return t.txn.Scan(prefix, func(_ string, value []byte) error {
+		return decode(value)
+	})
+}
+
+func (t *kvTx) CreatePlayer(p *types.Player) error {
+	err := t.getForUpdate(playerKey(p.Id), new(types.Player))
+	if err == nil {
+		return ErrExists
+	}
+	if err != ErrNotFound {
+		return err
+	}
+
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+
+	return t.put(playerKey(p.Id), p)
+}
+
+func (t *kvTx) SetPlayer(p *types.Player) error {
+	r := new(types.Player)
+
+	err := t.getForUpdate(playerKey(p.Id), r)
+	if err == ErrNotFound {
+		r.Id = p.Id
+		r.Status = types.PlayerActive
+		r.CreatedAt = time.Now()
+		r.UpdatedAt = r.CreatedAt
+	} else if err != nil {
+		return err
+	}
+
+	r.Points += p.Points
+
+	return t.put(playerKey(p.Id), r)
+}
+
+func (t *kvTx) GetPlayer(id string) (*types.Player, error) {
+	p := new(types.Player)
+	if err := t.get(playerKey(id), p); err != nil {
+		return p, err
+	}
+
+	return activated(p), t.loadHeld(p, false)
+}
+
+// activated marks a player stored before players had a status as active.
+func activated(p *types.Player) *types.Player {
+	if p.Status == "" {
+		p.Status = types.PlayerActive
+	}
+
+	return p
+}
+
+func (t *kvTx) GetPlayersForUpdate(ids []string) ([]*types.Player, error) {
+	pp := []*types.Player{}
+
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	for i, id := range sorted {
+		if i > 0 && sorted[i-1] == id {
+			continue
+		}
+
+		p := new(types.Player)
+		err := t.getForUpdate(playerKey(id), p)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return pp, err
+		}
+
+		if err = t.loadHeld(p, true); err != nil {
+			return pp, err
+		}
+
+		pp = append(pp, activated(p))
+	}
+
+	return pp, nil
+}
+
+// update replaces an existing record. Like UPDATE, it does nothing when
+// there is no record.
+func (t *kvTx) update(key string, v interface{}) error {
+	if err := t.lock(key); err != nil {
+		return err
+	}
+
+	if _, err := t.txn.Get(key); err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return t.put(key, v)
+}
+
+func (t *kvTx) UpdatePlayer(p *types.Player) error {
+	return t.update(playerKey(p.Id), p)
+}
+
+func (t *kvTx) UpdateProfile(p *types.Player) error {
+	r := new(types.Player)
+	if err := t.getForUpdate(playerKey(p.Id), r); err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	p.UpdatedAt = time.Now()
+	r.Name, r.Metadata, r.UpdatedAt = p.Name, p.Metadata, p.UpdatedAt
+	r.Status, r.StatusReason, r.StatusBy, r.StatusUntil = p.Status, p.StatusReason, p.StatusBy, p.StatusUntil
+
+	return t.put(playerKey(p.Id), r)
+}
+
+func (t *kvTx) SetTournament(tt *types.Tournament) error {
+	if tt.CreatedAt.IsZero() {
+		tt.CreatedAt = time.Now()
+	}
+
+	return t.put(tournamentKey(tt.Id), tt)
+}
+
+func (t *kvTx) GetTournament(id string) (*types.Tournament, error) {
+	tt := &types.Tournament{Entries: make(map[string]*types.Entry)}
+	if err := t.get(tournamentKey(id), tt); err != nil {
+		return tt, err
+	}
+
+	return tt, t.loadEntries(tt)
+}
+
+func (t *kvTx) GetTournamentForUpdate(id string) (*types.Tournament, error) {
+	tt := &types.Tournament{Entries: make(map[string]*types.Entry)}
+	if err := t.getForUpdate(tournamentKey(id), tt); err != nil {
+		return tt, err
+	}
+
+	return tt, t.loadEntries(tt)
+}
+
+// loadEntries fills in the entries of a tournament and their backings.
+func (t *kvTx) loadEntries(tt *types.Tournament) error {
+	id := tt.Id
+
+	err := t.scan(entryPrefix(id), func(value []byte) error {
+		e := new(types.Entry)
+		if err := json.Unmarshal(value, e); err != nil {
+			return err
+		}
+
+		if e.Number == 0 {
+			e.Number = 1
+		}
+
+		tt.Entries[e.Key()] = e
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.scan(backingPrefix(id), func(value []byte) error {
+		b := new(types.Backing)
+		if err := json.Unmarshal(value, b); err != nil {
+			return err
+		}
+
+		if b.Entry == 0 {
+			b.Entry = 1
+		}
+
+		if e, ok := tt.Entries[types.EntryKey(b.PlayerId, b.Entry)]; ok {
+			e.Backings = append(e.Backings, b)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (t *kvTx) UpdateTournament(tt *types.Tournament) error {
+	return t.update(tournamentKey(tt.Id), tt)
+}
+
+func (t *kvTx) ListTournaments(f types.TournamentFilter) ([]*types.Tournament, error) {
+	tt := []*types.Tournament{}
+
+	states := make(map[string]bool)
+	for _, s := range f.States {
+		states[s] = true
+	}
+
+	err := t.scan(tournamentKey(""), func(value []byte) error {
+		tr := new(types.Tournament)
+		if err := json.Unmarshal(value, tr); err != nil {
+			return err
+		}
+
+		if len(states) > 0 && !states[tr.State] ||
+			!f.From.IsZero() && tr.CreatedAt.Before(f.From) ||
+			!f.To.IsZero() && !tr.CreatedAt.Before(f.To) {
+			return nil
+		}
+
+		tt = append(tt, tr)
+		return nil
+	})
+	if err != nil {
+		return tt, err
+	}
+
+	// newest first, then by id, the way Postgres orders them
+	before := func(a, b *types.Tournament) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.Id < b.Id
+	}
+	sort.Slice(tt, func(i, j int) bool { return before(tt[i], tt[j]) })
+
+	if f.After != "" {
+		after := new(types.Tournament)
+		if err = t.get(tournamentKey(f.After), after); err == ErrNotFound {
+			return []*types.Tournament{}, nil
+		} else if err != nil {
+			return tt, err
+		}
+
+		i := sort.Search(len(tt), func(i int) bool { return before(after, tt[i]) })
+		tt = tt[i:]
+	}
+
+	if f.Limit > 0 && len(tt) > f.Limit {
+		tt = tt[:f.Limit]
+	}
+
+	return tt, nil
+}
+
+func (t *kvTx) AddTournamentEntry(e *types.Entry) error {
+	return t.put(entryKey(e), e)
+}
+
+func (t *kvTx) UpdateTournamentEntry(e *types.Entry) error {
+	if err := t.update(entryKey(e), e); err != nil {
+		return err
+	}
+
+	for _, b := range e.Backings {
+		if err := t.update(backingKey(b), b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *kvTx) AddBacking(b *types.Backing) error {
+	return t.put(backingKey(b), b)
+}
+
+func (t *kvTx) AddTransfer(tr *types.Transfer) error {
+	tr.CreatedAt = time.Now()
+
+	return t.put(transferKey(tr.Id), tr)
+}
+
+func (t *kvTx) GetTransfer(id string) (*types.Transfer, error) {
+	tr := new(types.Transfer)
+
+	return tr, t.get(transferKey(id), tr)
+}
+
+func (t *kvTx) GetTransferForUpdate(id string) (*types.Transfer, error) {
+	tr := new(types.Transfer)
+
+	return tr, t.getForUpdate(transferKey(id), tr)
+}
+
+func (t *kvTx) UpdateTransfer(tr *types.Transfer) error {
+	return t.update(transferKey(tr.Id), tr)
+}
+
+func (t *kvTx) TransferredSince(playerId string, since time.Time) (uint64, error) {
+	var sum uint64
+
+	err := t.scan(kvKey("transfer")+"/", func(value []byte) error {
+		tr := new(types.Transfer)
+		if err := json.Unmarshal(value, tr); err != nil {
+			return err
+		}
+
+		if tr.PlayerId == playerId && !tr.CreatedAt.Before(since) &&
+			(tr.Status == types.TransferPending || tr.Status == types.TransferCompleted) {
+			sum += tr.Amount
+		}
+
+		return nil
+	})
+
+	return sum, err
+}
+
+func (t *kvTx) AddHold(h *types.Hold) error {
+	h.CreatedAt = time.Now()
+
+	if err := t.put(holdKey(h.Id), h); err != nil {
+		return err
+	}
+
+	return t.txn.Set(playerHoldPrefix(h.PlayerId)+url.PathEscape(h.Id), []byte{})
+}
+
+func (t *kvTx) GetHold(id string) (*types.Hold, error) {
+	h := new(types.Hold)
+
+	return h, t.get(holdKey(id), h)
+}
+
+func (t *kvTx) GetHoldForUpdate(id string) (*types.Hold, error) {
+	h := new(types.Hold)
+
+	return h, t.getForUpdate(holdKey(id), h)
+}
+
+// UpdateHold drops a hold no longer active from the index of its player.
+func (t *kvTx) UpdateHold(h *types.Hold) error {
+	if err := t.update(holdKey(h.Id), h); err != nil {
+		return err
+	}
+
+	if h.Status == types.HoldActive {
+		return nil
+	}
+
+	return t.txn.Delete(playerHoldPrefix(h.PlayerId) + url.PathEscape(h.Id))
+}
+
+// loadHeld sums what the unexpired active holds of p reserve into p.Held.
+// With prune, p is locked and holds found expired leave its index.
+func (t *kvTx) loadHeld(p *types.Player, prune bool) error {
+	now := time.Now()
+	p.Held = 0
+
+	var stale []string
+	err := t.txn.Scan(playerHoldPrefix(p.Id), func(key string, _ []byte) error {
+		id, err := url.PathUnescape(key[strings.LastIndex(key, "/")+1:])
+		if err != nil {
+			return err
+		}
+
+		h := new(types.Hold)
+		if err = t.get(holdKey(id), h); err != nil {
+			return err
+		}
+
+		if h.StatusAt(now) == types.HoldActive {
+			p.Held += h.Amount
+		} else {
+			stale = append(stale, key)
+		}
+
+		return nil
+	})
+	if err != nil || !prune {
+		return err
+	}
+
+	for _, key := range stale {
+		if err = t.txn.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *kvTx) AddOffer(o *types.Offer) error {
+	o.CreatedAt = time.Now()
+	o.Purchases = []*types.Purchase{}
+
+	return t.put(offerKey(o.Id), o)
+}
+
+func (t *kvTx) GetOffer(id string) (*types.Offer, error) {
+	o := new(types.Offer)
+	if err := t.get(offerKey(id), o); err != nil {
+		return o, err
+	}
+
+	return o, t.loadPurchases(o)
+}
+
+func (t *kvTx) GetOfferForUpdate(id string) (*types.Offer, error) {
+	o := new(types.Offer)
+	if err := t.getForUpdate(offerKey(id), o); err != nil {
+		return o, err
+	}
+
+	return o, t.loadPurchases(o)
+}
+
+// loadPurchases fills in the purchases of an offer, ordered by backer id.
+// They are stored on their own, the offer keeps none of them.
+func (t *kvTx) loadPurchases(o *types.Offer) error {
+	o.Purchases = []*types.Purchase{}
+
+	return t.scan(purchasePrefix(o.Id), func(value []byte) error {
+		p := new(types.Purchase)
+		if err := json.Unmarshal(value, p); err != nil {
+			return err
+		}
+
+		o.Purchases = append(o.Purchases, p)
+		return nil
+	})
+}
+
+func (t *kvTx) UpdateOffer(o *types.Offer) error {
+	stored := *o
+	stored.Purchases = nil
+
+	return t.update(offerKey(o.Id), &stored)
+}
+
+func (t *kvTx) ListOffers(tournamentId string) ([]*types.Offer, error) {
+	oo := []*types.Offer{}
+
+	err := t.scan(kvKey("offer")+"/", func(value []byte) error {
+		o := new(types.Offer)
+		if err := json.Unmarshal(value, o); err != nil {
+			return err
+		}
+
+		if o.TournamentId == tournamentId {
+			oo = append(oo, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return oo, err
+	}
+
+	sort.SliceStable(oo, func(i, j int) bool { return oo[i].CreatedAt.Before(oo[j].CreatedAt) })
+
+	for _, o := range oo {
+		if err = t.loadPurchases(o); err != nil {
+			return oo, err
+		}
+	}
+
+	return oo, nil
+}
+
+func (t *kvTx) AddPurchase(p *types.Purchase) error {
+	err := t.getForUpdate(purchaseKey(p), new(types.Purchase))
+	if err == nil {
+		return ErrExists
+	}
+	if err != ErrNotFound {
+		return err
+	}
+
+	p.CreatedAt = time.Now()
+
+	return t.put(purchaseKey(p), p)
+}
+
+func ledgerEntryKey(id uint64) string {
+	return seqKey("ledger/entry/", id)
+}
+
+// ledgerAccountPrefix indexes the entries of an account. Account names are
+// escaped so that one name is never a prefix of another one's keys.
+func ledgerAccountPrefix(account string) string {
+	return "ledger/account/" + url.PathEscape(account) + "/"
+}
+
+func (t *kvTx) InsertLedgerEntry(e *types.LedgerEntry) error {
+	id, err := t.nextId("ledger")
+	if err != nil {
+		return err
+	}
+
+	e.Id = id
+	e.CreatedAt = time.Now().UTC()
+
+	if err = t.put(ledgerEntryKey(id), e); err != nil {
+		return err
+	}
+
+	for _, account := range []string{e.Debit, e.Credit} {
+		if err = t.txn.Set(seqKey(ledgerAccountPrefix(account), id), []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// accountEntries returns ids of the ledger entries of an account in id order.
+func (t *kvTx) accountEntries(account string) ([]uint64, error) {
+	var ids []uint64
+
+	err := t.txn.Scan(ledgerAccountPrefix(account), func(key string, _ []byte) error {
+		id, err := strconv.ParseUint(key[strings.LastIndex(key, "/")+1:], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+		return nil
+	})
+
+	return ids, err
+}
+
+func (t *kvTx) GetAccountBalance(account string) (int64, error) {
+	var balance int64
+
+	ids, err := t.accountEntries(account)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		var e types.LedgerEntry
+		if err = t.get(ledgerEntryKey(id), &e); err != nil {
+			return 0, err
+		}
+
+		if e.Credit == account {
+			balance += int64(e.Amount)
+		} else {
+			balance -= int64(e.Amount)
+		}
+	}
+
+	return balance, nil
+}
+
+func (t *kvTx) ListLedgerEntries(f types.LedgerFilter) ([]*types.LedgerEntry, error) {
+	ee := []*types.LedgerEntry{}
+
+	ids, err := t.accountEntries(f.Account)
+	if err != nil {
+		return ee, err
+	}
+
+	reasons := make(map[string]bool)
+	for _, r := range f.Reasons {
+		reasons[r] = true
+	}
+
+	for i := len(ids) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(ee) == f.Limit {
+			break
+		}
+		if f.Before > 0 && ids[i] >= f.Before {
+			continue
+		}
+
+		e := new(types.LedgerEntry)
+		if err = t.get(ledgerEntryKey(ids[i]), e); err != nil {
+			return ee, err
+		}
+
+		if len(reasons) > 0 && !reasons[e.Reason] ||
+			!f.From.IsZero() && e.CreatedAt.Before(f.From) ||
+			!f.To.IsZero() && !e.CreatedAt.Before(f.To) {
+			continue
+		}
+
+		ee = append(ee, e)
+	}
+
+	return ee, nil
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/xfreshx/lifland/lifecycle"
+	"github.com/xfreshx/lifland/types"
+	"sort"
+)
+
+// legacyKey marks a store whose records were moved out of the fields
+// tournaments and players kept before entries and backings had keys.
+const legacyKey = "schema/entries"
+
+// legacyTournament and legacyPlayer read those fields: the players of a
+// tournament, and the backers of a player.
+type legacyTournament struct {
+	Players map[string]interface{}
+}
+
+type legacyPlayer struct {
+	Backers map[string]interface{} `json:"backers"`
+}
+
+// migrateLegacy moves players and backers kept in tournament and player
+// records into entry and backing keys, and books balances kept before the
+// ledger as opening entries, the way the 0002 to 0006 migrations do for
+// Postgres. It runs once per store.
+func (s *kvStore) migrateLegacy() error {
+	return s.autocommit(func(tx *kvTx) error {
+		var done bool
+		err := tx.get(legacyKey, &done)
+		if err == nil {
+			return nil
+		}
+		if err != ErrNotFound {
+			return err
+		}
+
+		tournaments := make(map[string]*types.Tournament)
+		entrants := make(map[string][]string)
+		err = tx.txn.Scan(tournamentKey(""), func(_ string, value []byte) error {
+			var legacy legacyTournament
+			if err := json.Unmarshal(value, &legacy); err != nil {
+				return err
+			}
+
+			tt := new(types.Tournament)
+			if err := json.Unmarshal(value, tt); err != nil {
+				return err
+			}
+			if legacy.Players == nil && tt.State != "" {
+				return nil
+			}
+
+			tournaments[tt.Id] = tt
+			for id := range legacy.Players {
+				entrants[tt.Id] = append(entrants[tt.Id], id)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		players := make(map[string]*types.Player)
+		backers := make(map[string][]string)
+		err = tx.txn.Scan(playerKey(""), func(_ string, value []byte) error {
+			p := new(types.Player)
+			if err := json.Unmarshal(value, p); err != nil {
+				return err
+			}
+			players[p.Id] = p
+
+			var legacy legacyPlayer
+			if err := json.Unmarshal(value, &legacy); err != nil {
+				return err
+			}
+			if legacy.Backers != nil {
+				backers[p.Id] = []string{}
+			}
+			for id := range legacy.Backers {
+				backers[p.Id] = append(backers[p.Id], id)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		playerIds := make([]string, 0, len(players))
+		for id := range players {
+			playerIds = append(playerIds, id)
+		}
+		sort.Strings(playerIds)
+
+		// balances existing before the ledger are booked as opening entries
+		for _, id := range playerIds {
+			account := "player:" + id
+			booked, err := tx.accountEntries(account)
+			if err != nil {
+				return err
+			}
+			if players[id].Points == 0 || len(booked) > 0 {
+				continue
+			}
+
+			e := &types.LedgerEntry{
+				Debit:     "cashier",
+				Credit:    account,
+				Amount:    players[id].Points,
+				Reason:    "opening",
+				Reference: "migration",
+			}
+			if err = tx.InsertLedgerEntry(e); err != nil {
+				return err
+			}
+		}
+
+		ids := make([]string, 0, len(tournaments))
+		for id := range tournaments {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		// backers used to be kept per player, not per tournament, so they are
+		// attached to the first entry of the player
+		first := make(map[string]*types.Entry)
+		var entries []*types.Entry
+		for _, id := range ids {
+			tt := tournaments[id]
+			for _, playerId := range entrants[id] {
+				if players[playerId] == nil {
+					continue
+				}
+
+				e := &types.Entry{TournamentId: id, PlayerId: playerId, Number: 1, Contribution: tt.Deposit}
+				entries = append(entries, e)
+				if first[playerId] == nil {
+					first[playerId] = e
+				}
+			}
+		}
+
+		for playerId, backerIds := range backers {
+			e := first[playerId]
+			if e == nil {
+				continue
+			}
+
+			// each with an equal share of the deposit, whatever the backers
+			// did not pay was paid by the player
+			amount := tournaments[e.TournamentId].Deposit / uint64(len(backerIds)+1)
+			for _, backerId := range backerIds {
+				if players[backerId] == nil {
+					continue
+				}
+
+				b := &types.Backing{TournamentId: e.TournamentId, PlayerId: playerId, Entry: 1, BackerId: backerId, Amount: amount}
+				if err = tx.AddBacking(b); err != nil {
+					return err
+				}
+				if e.Contribution >= amount {
+					e.Contribution -= amount
+				} else {
+					e.Contribution = 0
+				}
+			}
+		}
+
+		for _, e := range entries {
+			if err = tx.AddTournamentEntry(e); err != nil {
+				return err
+			}
+		}
+
+		// tournaments announced so far were open for registration right away
+		for _, tt := range tournaments {
+			if tt.State == "" {
+				tt.State = lifecycle.RegistrationOpen
+			}
+			if err = tx.put(tournamentKey(tt.Id), tt); err != nil {
+				return err
+			}
+		}
+
+		for playerId := range backers {
+			if err = tx.put(playerKey(playerId), players[playerId]); err != nil {
+				return err
+			}
+		}
+
+		return tx.put(legacyKey, true)
+	})
+}
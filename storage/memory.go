@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NewMemoryStore creates an empty in-process store. Its data is lost when
+// the process exits.
+func NewMemoryStore() Store {
+	return newKVStore(&memoryEngine{data: make(map[string][]byte)})
+}
+
+type memoryEngine struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// memoryTxn buffers writes until Commit. A nil value marks a deleted key.
+type memoryTxn struct {
+	engine *memoryEngine
+	writes map[string][]byte
+}
+
+func (e *memoryEngine) Begin() kvTxn {
+	return &memoryTxn{engine: e, writes: make(map[string][]byte)}
+}
+
+func (e *memoryEngine) Reset() error {
+	e.mu.Lock()
+	e.data = make(map[string][]byte)
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *memoryEngine) Close() error {
+	return nil
+}
+
+func (t *memoryTxn) Get(key string) ([]byte, error) {
+	if v, ok := t.writes[key]; ok {
+		if v == nil {
+			return nil, ErrNotFound
+		}
+		return v, nil
+	}
+
+	t.engine.mu.RLock()
+	v, ok := t.engine.data[key]
+	t.engine.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (t *memoryTxn) Set(key string, value []byte) error {
+	t.writes[key] = value
+
+	return nil
+}
+
+func (t *memoryTxn) Delete(key string) error {
+	t.writes[key] = nil
+
+	return nil
+}
+
+func (t *memoryTxn) Scan(prefix string, fn func(key string, value []byte) error) error {
+	values := make(map[string][]byte)
+
+	t.engine.mu.RLock()
+	for key, v := range t.engine.data {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = v
+		}
+	}
+	t.engine.mu.RUnlock()
+
+	for key, v := range t.writes {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if v == nil {
+			delete(values, key)
+		} else {
+			values[key] = v
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := fn(key, values[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *memoryTxn) Commit() error {
+	t.engine.mu.Lock()
+	for key, v := range t.writes {
+		if v == nil {
+			delete(t.engine.data, key)
+		} else {
+			t.engine.data[key] = v
+		}
+	}
+	t.engine.mu.Unlock()
+
+	t.writes = nil
+
+	return nil
+}
+
+func (t *memoryTxn) Discard() {
+	t.writes = nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"github.com/xfreshx/lifland/types"
+	"testing"
+)
+
+func TestMemoryTx(t *testing.T) {
+	s := NewMemoryStore()
+
+	tx, err := s.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.CreatePlayer(&types.Player{Id: "a", Points: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// writes are not seen outside the transaction until it commits
+	if _, err = s.GetPlayer("a"); err != ErrNotFound {
+		t.Errorf("uncommitted player: error = %v, want %v", err, ErrNotFound)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.GetPlayer("a"); err != ErrNotFound {
+		t.Errorf("rolled back player: error = %v, want %v", err, ErrNotFound)
+	}
+
+	tx, err = s.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.CreatePlayer(&types.Player{Id: "a", Points: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("rollback after commit: error = %v", err)
+	}
+
+	p, err := s.GetPlayer("a")
+	if err != nil || p.Points != 100 {
+		t.Errorf("committed player = %v, %v", p, err)
+	}
+	if err = s.CreatePlayer(&types.Player{Id: "a"}); err != ErrExists {
+		t.Errorf("duplicate player: error = %v, want %v", err, ErrExists)
+	}
+}
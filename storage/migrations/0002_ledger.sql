@@ -0,0 +1,19 @@
+-- +migrate Up
+create table ledger (
+	id bigserial primary key,
+	debit text not null,
+	credit text not null,
+	amount bigint not null check (amount > 0),
+	reason text not null,
+	reference text not null default '',
+	created_at timestamptz not null default now()
+);
+
+create index ledger_debit_idx on ledger (debit, id);
+create index ledger_credit_idx on ledger (credit, id);
+
+-- balances existing before the ledger are booked as opening entries
+insert into ledger (debit, credit, amount, reason, reference)
+	select 'cashier', 'player:' || id, points, 'opening', 'migration'
+	from players
+	where points > 0;
@@ -0,0 +1,44 @@
+-- +migrate Up
+create table tournament_entries (
+	tournament_id text not null references tournaments (id) on delete cascade,
+	player_id text not null references players (id),
+	primary key (tournament_id, player_id)
+);
+
+create table backings (
+	tournament_id text not null,
+	player_id text not null,
+	backer_id text not null references players (id),
+	amount bigint not null default 0,
+	primary key (tournament_id, player_id, backer_id),
+	foreign key (tournament_id, player_id)
+		references tournament_entries (tournament_id, player_id) on delete cascade
+);
+
+create index backings_player_idx on backings (player_id);
+create index backings_backer_idx on backings (backer_id);
+
+insert into tournament_entries (tournament_id, player_id)
+	select t.id, e.key
+	from tournaments t,
+		json_each(case when json_typeof(t.players) = 'object' then t.players else '{}' end) e,
+		players p
+	where p.id = e.key;
+
+-- backers used to be kept per player, not per tournament, so they are
+-- attached to the first entry of the player with an equal share of its deposit
+insert into backings (tournament_id, player_id, backer_id, amount)
+	select te.tournament_id, p.id, b.key,
+		t.deposit / (select count(*) + 1 from json_object_keys(p.backers))
+	from players p,
+		json_each(case when json_typeof(p.backers) = 'object' then p.backers else '{}' end) b,
+		(select player_id, min(tournament_id) as tournament_id
+			from tournament_entries
+			group by player_id) te,
+		tournaments t
+	where te.player_id = p.id
+		and t.id = te.tournament_id
+		and exists (select 1 from players bp where bp.id = b.key);
+
+alter table tournaments drop column players;
+alter table players drop column backers;
@@ -0,0 +1,11 @@
+-- +migrate Up
+alter table tournament_entries add column contribution bigint not null default 0;
+
+-- whatever the backers did not pay was paid by the player
+update tournament_entries te
+	set contribution = greatest(t.deposit - coalesce((
+		select sum(b.amount)
+		from backings b
+		where b.tournament_id = te.tournament_id and b.player_id = te.player_id), 0), 0)
+	from tournaments t
+	where t.id = te.tournament_id;
@@ -0,0 +1,4 @@
+-- +migrate Up
+alter table tournaments add column rounding text not null default 'remainder-to-player';
+
+alter table ledger add column rounding text not null default '';
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- tournaments announced so far were open for registration right away
+alter table tournaments add column state text not null default 'registration_open';
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- tournaments are kept once they are finished, along with what they paid
+alter table tournaments add column created_at timestamptz not null default now();
+alter table tournaments add column finished_at timestamptz;
+alter table tournament_entries add column prize bigint not null default 0;
+alter table backings add column payout bigint not null default 0;
+
+create index tournaments_created_at_idx on tournaments (created_at);
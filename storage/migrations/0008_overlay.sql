@@ -0,0 +1,2 @@
+-- +migrate Up
+alter table tournaments add column overlay bigint not null default 0;
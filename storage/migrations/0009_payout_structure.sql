@@ -0,0 +1,2 @@
+-- +migrate Up
+alter table tournaments add column payout text not null default '';
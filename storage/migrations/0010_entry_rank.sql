@@ -0,0 +1,2 @@
+-- +migrate Up
+alter table tournament_entries add column rank integer not null default 0;
@@ -0,0 +1,4 @@
+-- +migrate Up
+alter table tournaments add column rake bigint not null default 0;
+alter table tournaments add column rake_basis_points bigint not null default 0;
+alter table tournament_entries add column rake bigint not null default 0;
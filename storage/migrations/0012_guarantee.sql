@@ -0,0 +1,2 @@
+-- +migrate Up
+alter table tournaments add column guarantee bigint not null default 0;
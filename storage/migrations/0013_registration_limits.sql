@@ -0,0 +1,8 @@
+-- +migrate Up
+alter table tournaments add column max_entrants integer not null default 0;
+alter table tournaments add column min_entrants integer not null default 0;
+alter table tournaments add column registration_opens_at timestamptz;
+alter table tournaments add column registration_closes_at timestamptz;
+-- in seconds
+alter table tournaments add column late_registration bigint not null default 0;
+alter table tournaments add column started_at timestamptz;
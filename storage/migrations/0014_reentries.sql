@@ -0,0 +1,17 @@
+-- +migrate Up
+-- a player may enter a tournament more than once, entries are numbered per player
+alter table backings drop constraint backings_tournament_id_player_id_fkey;
+alter table backings drop constraint backings_pkey;
+alter table tournament_entries drop constraint tournament_entries_pkey;
+
+alter table tournament_entries add column entry integer not null default 1;
+alter table tournament_entries add column rebuys integer not null default 0;
+alter table tournament_entries add primary key (tournament_id, player_id, entry);
+
+alter table backings add column entry integer not null default 1;
+alter table backings add primary key (tournament_id, player_id, entry, backer_id);
+alter table backings add foreign key (tournament_id, player_id, entry)
+	references tournament_entries (tournament_id, player_id, entry) on delete cascade;
+
+alter table tournaments add column reentries integer not null default 0;
+alter table tournaments add column rebuys integer not null default 0;
@@ -0,0 +1,6 @@
+-- +migrate Up
+alter table players add column name text not null default '';
+alter table players add column metadata jsonb not null default '{}';
+alter table players add column status text not null default 'active';
+alter table players add column created_at timestamptz not null default now();
+alter table players add column updated_at timestamptz not null default now();
@@ -0,0 +1,4 @@
+-- +migrate Up
+alter table players add column status_reason text not null default '';
+alter table players add column status_by text not null default '';
+alter table players add column status_until timestamptz;
@@ -0,0 +1,13 @@
+-- +migrate Up
+create table transfers (
+	id text primary key,
+	player_id text not null references players (id),
+	recipient_id text not null references players (id),
+	amount bigint not null check (amount > 0),
+	status text not null,
+	created_at timestamptz not null default now(),
+	settled_at timestamptz
+);
+
+create index transfers_player_idx on transfers (player_id, created_at);
+create index transfers_recipient_idx on transfers (recipient_id);
@@ -0,0 +1,13 @@
+-- +migrate Up
+create table holds (
+	id text primary key,
+	player_id text not null references players (id),
+	amount bigint not null check (amount > 0),
+	captured bigint not null default 0,
+	status text not null,
+	expires_at timestamptz not null,
+	created_at timestamptz not null default now(),
+	settled_at timestamptz
+);
+
+create index holds_active_idx on holds (player_id) where status = 'active';
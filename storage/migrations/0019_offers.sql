@@ -0,0 +1,25 @@
+-- +migrate Up
+create table offers (
+	id text primary key,
+	tournament_id text not null references tournaments (id),
+	player_id text not null references players (id),
+	share bigint not null check (share > 0 and share <= 10000),
+	markup bigint not null default 0,
+	deadline timestamptz not null,
+	status text not null,
+	entry integer not null default 0,
+	created_at timestamptz not null default now()
+);
+
+create index offers_tournament_idx on offers (tournament_id, created_at);
+
+create table purchases (
+	offer_id text not null references offers (id),
+	backer_id text not null references players (id),
+	share bigint not null check (share > 0),
+	amount bigint not null,
+	premium bigint not null default 0,
+	hold_id text not null references holds (id),
+	created_at timestamptz not null default now(),
+	primary key (offer_id, backer_id)
+);
@@ -0,0 +1,2 @@
+-- +migrate Up
+alter table backings add column premium bigint not null default 0;
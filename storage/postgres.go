@@ -0,0 +1,690 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rubenv/sql-migrate"
+	"github.com/xfreshx/lifland/types"
+	"strings"
+	"time"
+)
+
+//TODO: go-bindata -pkg storage migrations/... must be included in a build process
+
+// querier is implemented by both *sql.DB and *sql.Tx, so the same queries
+// run either on the pool or on the connection of a transaction.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type postgresStore struct {
+	postgresQueries
+	db *sql.DB
+}
+
+type postgresTx struct {
+	postgresQueries
+	tx *sql.Tx
+}
+
+type postgresQueries struct {
+	q querier
+}
+
+// NewPostgresStore opens a Postgres database by the given connection string
+// and applies pending migrations.
+func NewPostgresStore(dbConnStr string) (Store, error) {
+	db, err := sql.Open("postgres", dbConnStr)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := &migrate.AssetMigrationSource{
+		Asset:    Asset,
+		AssetDir: AssetDir,
+		Dir:      "migrations",
+	}
+
+	_, err = migrate.Exec(db, "postgres", migrations, migrate.Up)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &postgresStore{postgresQueries: postgresQueries{q: db}, db: db}, nil
+}
+
+// noRows translates sql.ErrNoRows into the backend independent ErrNotFound.
+func noRows(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (s *postgresStore) Begin() (Tx, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresTx{postgresQueries: postgresQueries{q: tx}, tx: tx}, nil
+}
+
+func (s *postgresStore) Reset() error {
+	_, err := s.db.Exec("TRUNCATE purchases, offers, holds, transfers, backings, tournament_entries, tournaments, players, ledger;")
+
+	return err
+}
+
+func (s *postgresStore) Close() {
+	_ = s.db.Close()
+}
+
+func (t *postgresTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *postgresTx) Rollback() error {
+	err := t.tx.Rollback()
+	if err == sql.ErrTxDone {
+		return nil
+	}
+
+	return err
+}
+
+// tournamentSettings are the columns of a tournament besides its id and
+// creation time, in the order of tournamentValues.
+var tournamentSettings = []string{
+	"deposit", "rounding", "state", "overlay", "payout", "rake", "rake_basis_points", "guarantee",
+	"max_entrants", "min_entrants", "registration_opens_at", "registration_closes_at", "late_registration",
+	"reentries", "rebuys", "started_at", "finished_at",
+}
+
+var tournamentColumns = "id, " + strings.Join(tournamentSettings, ", ") + ", created_at"
+
+func tournamentValues(t *types.Tournament) []interface{} {
+	return []interface{}{
+		t.Id, t.Deposit, t.Rounding, t.State, t.Overlay, t.Payout, t.Rake, t.RakeBasisPoints, t.Guarantee,
+		t.MaxEntrants, t.MinEntrants, t.RegistrationOpensAt, t.RegistrationClosesAt,
+		int64(t.LateRegistration / time.Second), t.Reentries, t.Rebuys, t.StartedAt, t.FinishedAt,
+	}
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTournament reads a row of tournamentColumns.
+func scanTournament(row scanner, t *types.Tournament) error {
+	var late int64
+
+	err := row.Scan(&t.Id, &t.Deposit, &t.Rounding, &t.State, &t.Overlay, &t.Payout, &t.Rake, &t.RakeBasisPoints,
+		&t.Guarantee, &t.MaxEntrants, &t.MinEntrants, &t.RegistrationOpensAt, &t.RegistrationClosesAt,
+		&late, &t.Reentries, &t.Rebuys, &t.StartedAt, &t.FinishedAt, &t.CreatedAt)
+	t.LateRegistration = time.Duration(late) * time.Second
+
+	return err
+}
+
+// SetTournament creates a tournament or changes the settings of an existing one.
+func (s postgresQueries) SetTournament(t *types.Tournament) error {
+	params := make([]string, len(tournamentSettings)+1)
+	updates := make([]string, len(tournamentSettings))
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	for i, c := range tournamentSettings {
+		updates[i] = c + " = EXCLUDED." + c
+	}
+
+	return s.q.QueryRow(
+		`INSERT INTO tournaments (id, `+strings.Join(tournamentSettings, ", ")+`)
+			VALUES (`+strings.Join(params, ", ")+`)
+			ON CONFLICT (id)
+			DO UPDATE
+				SET `+strings.Join(updates, ", ")+`
+			RETURNING created_at;`,
+		tournamentValues(t)...).Scan(&t.CreatedAt)
+}
+
+func (s postgresQueries) GetTournament(id string) (*types.Tournament, error) {
+	return s.getTournament(id, "")
+}
+
+func (s postgresQueries) GetTournamentForUpdate(id string) (*types.Tournament, error) {
+	return s.getTournament(id, " FOR UPDATE")
+}
+
+func (s postgresQueries) getTournament(id string, lock string) (*types.Tournament, error) {
+	t := types.Tournament{Entries: make(map[string]*types.Entry)}
+
+	row := s.q.QueryRow("SELECT "+tournamentColumns+" FROM tournaments WHERE id = $1"+lock+";", id)
+	if err := scanTournament(row, &t); err != nil {
+		return &t, noRows(err)
+	}
+
+	rows, err := s.q.Query(
+		`SELECT player_id, entry, rebuys, contribution, rake, prize, rank
+			FROM tournament_entries WHERE tournament_id = $1;`, id)
+	if err != nil {
+		return &t, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := &types.Entry{TournamentId: id}
+		err = rows.Scan(&e.PlayerId, &e.Number, &e.Rebuys, &e.Contribution, &e.Rake, &e.Prize, &e.Rank)
+		if err != nil {
+			return &t, err
+		}
+
+		t.Entries[e.Key()] = e
+	}
+
+	if err = rows.Err(); err != nil {
+		return &t, err
+	}
+
+	rows, err = s.q.Query(
+		`SELECT player_id, entry, backer_id, amount, premium, payout
+			FROM backings WHERE tournament_id = $1 ORDER BY backer_id;`, id)
+	if err != nil {
+		return &t, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b := &types.Backing{TournamentId: id}
+		if err = rows.Scan(&b.PlayerId, &b.Entry, &b.BackerId, &b.Amount, &b.Premium, &b.Payout); err != nil {
+			return &t, err
+		}
+
+		if e, ok := t.Entries[types.EntryKey(b.PlayerId, b.Entry)]; ok {
+			e.Backings = append(e.Backings, b)
+		}
+	}
+
+	return &t, rows.Err()
+}
+
+func (s postgresQueries) UpdateTournament(t *types.Tournament) error {
+	updates := make([]string, len(tournamentSettings))
+	for i, c := range tournamentSettings {
+		updates[i] = fmt.Sprintf("%s = $%d", c, i+2)
+	}
+
+	_, err := s.q.Exec("UPDATE tournaments SET "+strings.Join(updates, ", ")+" WHERE id = $1;",
+		tournamentValues(t)...)
+
+	return err
+}
+
+func (s postgresQueries) ListTournaments(f types.TournamentFilter) ([]*types.Tournament, error) {
+	tt := []*types.Tournament{}
+
+	query := "SELECT " + tournamentColumns + " FROM tournaments WHERE true"
+	var args []interface{}
+
+	cond := func(c string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+c, len(args))
+	}
+
+	if len(f.States) > 0 {
+		cond("state = ANY($%d::text[])", pq.Array(f.States))
+	}
+	if !f.From.IsZero() {
+		cond("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		cond("created_at < $%d", f.To)
+	}
+
+	if f.After != "" {
+		cond(`EXISTS (SELECT 1 FROM tournaments c WHERE c.id = $%d
+			AND (tournaments.created_at < c.created_at
+				OR tournaments.created_at = c.created_at AND tournaments.id > c.id))`, f.After)
+	}
+
+	query += " ORDER BY created_at DESC, id"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.q.Query(query+";", args...)
+	if err != nil {
+		return tt, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t := new(types.Tournament)
+
+		if err = scanTournament(rows, t); err != nil {
+			return tt, err
+		}
+
+		tt = append(tt, t)
+	}
+
+	return tt, rows.Err()
+}
+
+func (s postgresQueries) AddTournamentEntry(e *types.Entry) error {
+	_, err := s.q.Exec(
+		`INSERT INTO tournament_entries (tournament_id, player_id, entry, rebuys, contribution, rake)
+			VALUES ($1, $2, $3, $4, $5, $6);`,
+		e.TournamentId, e.PlayerId, e.Number, e.Rebuys, e.Contribution, e.Rake)
+
+	return err
+}
+
+func (s postgresQueries) UpdateTournamentEntry(e *types.Entry) error {
+	_, err := s.q.Exec(
+		`UPDATE tournament_entries SET rebuys = $4, contribution = $5, rake = $6, prize = $7, rank = $8
+			WHERE tournament_id = $1 AND player_id = $2 AND entry = $3;`,
+		e.TournamentId, e.PlayerId, e.Number, e.Rebuys, e.Contribution, e.Rake, e.Prize, e.Rank)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range e.Backings {
+		_, err = s.q.Exec(
+			`UPDATE backings SET payout = $5
+				WHERE tournament_id = $1 AND player_id = $2 AND entry = $3 AND backer_id = $4;`,
+			b.TournamentId, b.PlayerId, b.Entry, b.BackerId, b.Payout)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s postgresQueries) AddBacking(b *types.Backing) error {
+	_, err := s.q.Exec(
+		`INSERT INTO backings (tournament_id, player_id, entry, backer_id, amount, premium)
+			VALUES ($1, $2, $3, $4, $5, $6);`,
+		b.TournamentId, b.PlayerId, b.Entry, b.BackerId, b.Amount, b.Premium)
+
+	return err
+}
+
+func (s postgresQueries) AddTransfer(tr *types.Transfer) error {
+	return s.q.QueryRow(
+		`INSERT INTO transfers (id, player_id, recipient_id, amount, status, settled_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING created_at;`,
+		tr.Id, tr.PlayerId, tr.RecipientId, tr.Amount, tr.Status, tr.SettledAt).Scan(&tr.CreatedAt)
+}
+
+func (s postgresQueries) GetTransfer(id string) (*types.Transfer, error) {
+	return s.getTransfer(id, "")
+}
+
+func (s postgresQueries) GetTransferForUpdate(id string) (*types.Transfer, error) {
+	return s.getTransfer(id, " FOR UPDATE")
+}
+
+func (s postgresQueries) getTransfer(id string, lock string) (*types.Transfer, error) {
+	var tr types.Transfer
+
+	err := s.q.QueryRow(
+		`SELECT id, player_id, recipient_id, amount, status, created_at, settled_at
+			FROM transfers WHERE id = $1`+lock+`;`, id).
+		Scan(&tr.Id, &tr.PlayerId, &tr.RecipientId, &tr.Amount, &tr.Status, &tr.CreatedAt, &tr.SettledAt)
+
+	return &tr, noRows(err)
+}
+
+func (s postgresQueries) UpdateTransfer(tr *types.Transfer) error {
+	_, err := s.q.Exec(`UPDATE transfers SET status = $2, settled_at = $3 WHERE id = $1;`,
+		tr.Id, tr.Status, tr.SettledAt)
+
+	return err
+}
+
+func (s postgresQueries) TransferredSince(playerId string, since time.Time) (uint64, error) {
+	var sum uint64
+
+	err := s.q.QueryRow(
+		`SELECT coalesce(sum(amount), 0)
+			FROM transfers
+			WHERE player_id = $1 AND created_at >= $2 AND status = ANY($3::text[]);`,
+		playerId, since, pq.Array([]string{types.TransferPending, types.TransferCompleted})).Scan(&sum)
+
+	return sum, err
+}
+
+func (s postgresQueries) AddHold(h *types.Hold) error {
+	return s.q.QueryRow(
+		`INSERT INTO holds (id, player_id, amount, status, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING created_at;`,
+		h.Id, h.PlayerId, h.Amount, h.Status, h.ExpiresAt).Scan(&h.CreatedAt)
+}
+
+func (s postgresQueries) GetHold(id string) (*types.Hold, error) {
+	return s.getHold(id, "")
+}
+
+func (s postgresQueries) GetHoldForUpdate(id string) (*types.Hold, error) {
+	return s.getHold(id, " FOR UPDATE")
+}
+
+func (s postgresQueries) getHold(id string, lock string) (*types.Hold, error) {
+	var h types.Hold
+
+	err := s.q.QueryRow(
+		`SELECT id, player_id, amount, captured, status, expires_at, created_at, settled_at
+			FROM holds WHERE id = $1`+lock+`;`, id).
+		Scan(&h.Id, &h.PlayerId, &h.Amount, &h.Captured, &h.Status, &h.ExpiresAt, &h.CreatedAt, &h.SettledAt)
+
+	return &h, noRows(err)
+}
+
+func (s postgresQueries) UpdateHold(h *types.Hold) error {
+	_, err := s.q.Exec(`UPDATE holds SET captured = $2, status = $3, settled_at = $4 WHERE id = $1;`,
+		h.Id, h.Captured, h.Status, h.SettledAt)
+
+	return err
+}
+
+func (s postgresQueries) AddOffer(o *types.Offer) error {
+	return s.q.QueryRow(
+		`INSERT INTO offers (id, tournament_id, player_id, share, markup, deadline, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING created_at;`,
+		o.Id, o.TournamentId, o.PlayerId, o.Share, o.Markup, o.Deadline, o.Status).Scan(&o.CreatedAt)
+}
+
+func (s postgresQueries) GetOffer(id string) (*types.Offer, error) {
+	return s.getOffer(id, "")
+}
+
+func (s postgresQueries) GetOfferForUpdate(id string) (*types.Offer, error) {
+	return s.getOffer(id, " FOR UPDATE")
+}
+
+const offerColumns = "id, tournament_id, player_id, share, markup, deadline, status, entry, created_at"
+
+func scanOffer(row scanner, o *types.Offer) error {
+	return row.Scan(&o.Id, &o.TournamentId, &o.PlayerId, &o.Share, &o.Markup, &o.Deadline, &o.Status, &o.Entry,
+		&o.CreatedAt)
+}
+
+func (s postgresQueries) getOffer(id string, lock string) (*types.Offer, error) {
+	var o types.Offer
+
+	row := s.q.QueryRow("SELECT "+offerColumns+" FROM offers WHERE id = $1"+lock+";", id)
+	if err := scanOffer(row, &o); err != nil {
+		return &o, noRows(err)
+	}
+
+	return &o, s.loadPurchases([]*types.Offer{&o})
+}
+
+func (s postgresQueries) ListOffers(tournamentId string) ([]*types.Offer, error) {
+	oo := []*types.Offer{}
+
+	rows, err := s.q.Query(
+		"SELECT "+offerColumns+" FROM offers WHERE tournament_id = $1 ORDER BY created_at, id;", tournamentId)
+	if err != nil {
+		return oo, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		o := new(types.Offer)
+		if err = scanOffer(rows, o); err != nil {
+			return oo, err
+		}
+
+		oo = append(oo, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return oo, err
+	}
+
+	return oo, s.loadPurchases(oo)
+}
+
+// loadPurchases fills in the purchases of the offers, ordered by backer id.
+func (s postgresQueries) loadPurchases(oo []*types.Offer) error {
+	offers := make(map[string]*types.Offer)
+	ids := make([]string, 0, len(oo))
+	for _, o := range oo {
+		o.Purchases = []*types.Purchase{}
+		offers[o.Id] = o
+		ids = append(ids, o.Id)
+	}
+
+	rows, err := s.q.Query(
+		`SELECT offer_id, backer_id, share, amount, premium, hold_id, created_at
+			FROM purchases WHERE offer_id = ANY($1::text[])
+			ORDER BY backer_id;`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := new(types.Purchase)
+		err = rows.Scan(&p.OfferId, &p.BackerId, &p.Share, &p.Amount, &p.Premium, &p.HoldId, &p.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		offers[p.OfferId].Purchases = append(offers[p.OfferId].Purchases, p)
+	}
+
+	return rows.Err()
+}
+
+func (s postgresQueries) UpdateOffer(o *types.Offer) error {
+	_, err := s.q.Exec(`UPDATE offers SET status = $2, entry = $3 WHERE id = $1;`, o.Id, o.Status, o.Entry)
+
+	return err
+}
+
+func (s postgresQueries) AddPurchase(p *types.Purchase) error {
+	err := s.q.QueryRow(
+		`INSERT INTO purchases (offer_id, backer_id, share, amount, premium, hold_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (offer_id, backer_id) DO NOTHING
+			RETURNING created_at;`,
+		p.OfferId, p.BackerId, p.Share, p.Amount, p.Premium, p.HoldId).Scan(&p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrExists
+	}
+
+	return err
+}
+
+// playerColumns end with what the active holds of the player reserve.
+const playerColumns = "id, points, name, metadata, status, status_reason, status_by, status_until, created_at, updated_at, " +
+	"(SELECT coalesce(sum(amount), 0) FROM holds WHERE player_id = players.id AND status = 'active' AND expires_at > now())"
+
+// scanPlayer reads a row of playerColumns.
+func scanPlayer(row scanner, p *types.Player) error {
+	var metadata []byte
+
+	err := row.Scan(&p.Id, &p.Points, &p.Name, &metadata, &p.Status, &p.StatusReason, &p.StatusBy, &p.StatusUntil,
+		&p.CreatedAt, &p.UpdatedAt, &p.Held)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(metadata, &p.Metadata)
+}
+
+func (s postgresQueries) CreatePlayer(p *types.Player) error {
+	metadata, err := json.Marshal(p.Metadata)
+	if err != nil {
+		return err
+	}
+
+	err = s.q.QueryRow(
+		`INSERT INTO players (id, points, name, metadata, status)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO NOTHING
+			RETURNING created_at, updated_at;`,
+		p.Id, p.Points, p.Name, metadata, p.Status).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrExists
+	}
+
+	return err
+}
+
+// GetPlayersForUpdate locks the players in id order, so that concurrent
+// transactions locking overlapping sets of players cannot deadlock.
+func (s postgresQueries) GetPlayersForUpdate(ids []string) ([]*types.Player, error) {
+	pp := []*types.Player{}
+
+	rows, err := s.q.Query(
+		"SELECT "+playerColumns+" FROM players WHERE id = ANY($1::text[]) ORDER BY id FOR UPDATE;",
+		pq.Array(ids))
+	if err != nil {
+		return pp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := new(types.Player)
+
+		if err = scanPlayer(rows, p); err != nil {
+			return pp, err
+		}
+
+		pp = append(pp, p)
+	}
+
+	return pp, rows.Err()
+}
+
+func (s postgresQueries) GetPlayer(id string) (*types.Player, error) {
+	var p types.Player
+
+	row := s.q.QueryRow("SELECT "+playerColumns+" FROM players WHERE id = $1;", id)
+	if err := scanPlayer(row, &p); err != nil {
+		return &p, noRows(err)
+	}
+
+	return &p, nil
+}
+
+func (s postgresQueries) UpdatePlayer(p *types.Player) error {
+	_, err := s.q.Exec(`UPDATE players SET points = $2 WHERE id = $1;`, p.Id, p.Points)
+
+	return err
+}
+
+func (s postgresQueries) UpdateProfile(p *types.Player) error {
+	metadata, err := json.Marshal(p.Metadata)
+	if err != nil {
+		return err
+	}
+
+	return s.q.QueryRow(
+		`UPDATE players SET name = $2, metadata = $3,
+				status = $4, status_reason = $5, status_by = $6, status_until = $7, updated_at = now()
+			WHERE id = $1
+			RETURNING updated_at;`,
+		p.Id, p.Name, metadata, p.Status, p.StatusReason, p.StatusBy, p.StatusUntil).Scan(&p.UpdatedAt)
+}
+
+// SetPlayer creates a player or adds p.Points to the balance of an existing one.
+func (s postgresQueries) SetPlayer(p *types.Player) error {
+	_, err := s.q.Exec(
+		`INSERT INTO players (id, points)
+			VALUES ($1, $2)
+			ON CONFLICT (id)
+			DO UPDATE
+				SET points = players.points + EXCLUDED.points;`,
+		p.Id, p.Points)
+
+	return err
+}
+
+func (s postgresQueries) InsertLedgerEntry(e *types.LedgerEntry) error {
+	return s.q.QueryRow(
+		`INSERT INTO ledger (debit, credit, amount, reason, reference, rounding)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at;`,
+		e.Debit, e.Credit, e.Amount, e.Reason, e.Reference, e.Rounding).Scan(&e.Id, &e.CreatedAt)
+}
+
+func (s postgresQueries) GetAccountBalance(account string) (int64, error) {
+	var balance int64
+
+	err := s.q.QueryRow(
+		`SELECT coalesce(sum(CASE WHEN credit = $1 THEN amount ELSE -amount END), 0)
+			FROM ledger
+			WHERE credit = $1 OR debit = $1;`,
+		account).Scan(&balance)
+
+	return balance, err
+}
+
+func (s postgresQueries) ListLedgerEntries(f types.LedgerFilter) ([]*types.LedgerEntry, error) {
+	ee := []*types.LedgerEntry{}
+
+	query := `SELECT id, debit, credit, amount, reason, reference, rounding, created_at
+		FROM ledger
+		WHERE (debit = $1 OR credit = $1)`
+	args := []interface{}{f.Account}
+
+	cond := func(c string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+c, len(args))
+	}
+
+	if len(f.Reasons) > 0 {
+		cond("reason = ANY($%d::text[])", pq.Array(f.Reasons))
+	}
+	if !f.From.IsZero() {
+		cond("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		cond("created_at < $%d", f.To)
+	}
+	if f.Before > 0 {
+		cond("id < $%d", f.Before)
+	}
+
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.q.Query(query+";", args...)
+	if err != nil {
+		return ee, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := new(types.LedgerEntry)
+
+		err = rows.Scan(&e.Id, &e.Debit, &e.Credit, &e.Amount, &e.Reason, &e.Reference, &e.Rounding, &e.CreatedAt)
+		if err != nil {
+			return ee, err
+		}
+
+		ee = append(ee, e)
+	}
+
+	return ee, rows.Err()
+}
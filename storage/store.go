@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"errors"
+	"github.com/xfreshx/lifland/types"
+	"time"
+)
+
+var ErrNotFound = errors.New("not found")
+
+var ErrExists = errors.New("already exists")
+
+// Config selects a storage backend.
+type Config struct {
+	// Backend is one of "postgres", "memory" or "badger". Empty means postgres.
+	Backend string
+	// DSN is a Postgres connection string.
+	DSN string
+	// Dir is a Badger data directory.
+	Dir string
+}
+
+// Open creates the store chosen by the config.
+func Open(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "", "postgres":
+		return NewPostgresStore(cfg.DSN)
+	case "memory":
+		return NewMemoryStore(), nil
+	case "badger":
+		return NewBadgerStore(cfg.Dir)
+	}
+
+	return nil, errors.New("unknown storage backend " + cfg.Backend)
+}
+
+// Store is a storage backend for players and tournaments.
+type Store interface {
+	Queries
+
+	// Begin starts a unit of work. Every call made through the returned Tx
+	// runs in the same database transaction.
+	Begin() (Tx, error)
+
+	Reset() error
+	Close()
+}
+
+// Tx is a storage transaction. Rollback after a successful Commit is a no-op,
+// so it is safe to defer.
+type Tx interface {
+	Queries
+
+	Commit() error
+	Rollback() error
+}
+
+// Queries are the operations available both on a Store and inside a Tx.
+// The ForUpdate methods lock the returned rows until the end of a transaction.
+type Queries interface {
+	// CreatePlayer registers a new player and sets its timestamps. It fails
+	// with ErrExists when the id is taken.
+	CreatePlayer(p *types.Player) error
+	SetPlayer(p *types.Player) error
+	// GetPlayer and GetPlayersForUpdate load players with what their
+	// unexpired active holds reserve.
+	GetPlayer(id string) (*types.Player, error)
+	GetPlayersForUpdate(ids []string) ([]*types.Player, error)
+	UpdatePlayer(p *types.Player) error
+	// UpdateProfile saves the name, metadata and status of a player, with the
+	// reason, actor and expiry of the status, and sets its UpdatedAt.
+	UpdateProfile(p *types.Player) error
+
+	SetTournament(t *types.Tournament) error
+	// GetTournament loads a tournament with its entries and their backings.
+	GetTournament(id string) (*types.Tournament, error)
+	GetTournamentForUpdate(id string) (*types.Tournament, error)
+	UpdateTournament(t *types.Tournament) error
+	// ListTournaments returns tournaments matching the filter without their entries.
+	ListTournaments(f types.TournamentFilter) ([]*types.Tournament, error)
+	AddTournamentEntry(e *types.Entry) error
+	// UpdateTournamentEntry saves the result of an entry and the payouts of its backings.
+	UpdateTournamentEntry(e *types.Entry) error
+
+	AddBacking(b *types.Backing) error
+
+	// AddTransfer saves a new transfer and sets its CreatedAt.
+	AddTransfer(tr *types.Transfer) error
+	GetTransfer(id string) (*types.Transfer, error)
+	GetTransferForUpdate(id string) (*types.Transfer, error)
+	// UpdateTransfer saves the status of a transfer and when it was settled.
+	UpdateTransfer(tr *types.Transfer) error
+	// TransferredSince sums the transfers a player sent from since on,
+	// leaving out the declined and cancelled ones.
+	TransferredSince(playerId string, since time.Time) (uint64, error)
+
+	// AddHold saves a new hold and sets its CreatedAt.
+	AddHold(h *types.Hold) error
+	GetHold(id string) (*types.Hold, error)
+	GetHoldForUpdate(id string) (*types.Hold, error)
+	// UpdateHold saves the status of a hold, what was captured and when.
+	UpdateHold(h *types.Hold) error
+
+	// AddOffer saves a new offer and sets its CreatedAt.
+	AddOffer(o *types.Offer) error
+	// GetOffer loads an offer with its purchases.
+	GetOffer(id string) (*types.Offer, error)
+	GetOfferForUpdate(id string) (*types.Offer, error)
+	// UpdateOffer saves the status of an offer and the entry it was taken up with.
+	UpdateOffer(o *types.Offer) error
+	// ListOffers returns the offers of a tournament with their purchases, oldest first.
+	ListOffers(tournamentId string) ([]*types.Offer, error)
+	// AddPurchase saves a new purchase and sets its CreatedAt. It fails with
+	// ErrExists when the backer already bought a share of the offer.
+	AddPurchase(p *types.Purchase) error
+
+	// InsertLedgerEntry appends an entry to the ledger and sets its Id and CreatedAt.
+	InsertLedgerEntry(e *types.LedgerEntry) error
+	// GetAccountBalance derives the balance of an account from the ledger:
+	// the sum of its credits minus the sum of its debits.
+	GetAccountBalance(account string) (int64, error)
+	// ListLedgerEntries returns entries of an account matching the filter, newest first.
+	ListLedgerEntries(f types.LedgerFilter) ([]*types.LedgerEntry, error)
+}
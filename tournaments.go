@@ -0,0 +1,784 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/lifecycle"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TransitionHandler returns a handler performing op on the tournament given
+// by tournamentId, such as opening or closing its registration.
+func (h *Handlers) TransitionHandler(op lifecycle.Operation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+
+		tournamentId, err := utils.GetStringURLParam(params, "tournamentId")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid tournamentId given")
+			return
+		}
+
+		tx, err := h.store.Begin()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+		defer tx.Rollback()
+
+		t, err := tx.GetTournamentForUpdate(tournamentId)
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such tournament")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		err = transition(tx, t, op)
+		if err == lifecycle.ErrNotAllowed {
+			w.WriteHeader(http.StatusConflict)
+			log.Println("tournament can not " + string(op) + " in state " + t.State)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.UpdateTournament(t); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if op == lifecycle.Start && t.State == lifecycle.Cancelled {
+			w.WriteHeader(http.StatusConflict)
+			log.Println("tournament did not reach its minimum field and was cancelled")
+			_, _ = w.Write([]byte("tournament did not reach its minimum field and was cancelled"))
+		}
+	}
+}
+
+// transition performs op on t. A tournament short of its minimum field is
+// cancelled and refunded instead of starting.
+func transition(tx storage.Tx, t *types.Tournament, op lifecycle.Operation) error {
+	if op == lifecycle.Cancel {
+		return cancel(tx, t)
+	}
+
+	if err := lifecycle.Apply(t, op); err != nil {
+		return err
+	}
+
+	if op == lifecycle.Start {
+		now := time.Now()
+		t.StartedAt = &now
+
+		// there is no scheduler, the field is checked when a start or a
+		// result is requested
+		if t.Entrants() < t.MinEntrants {
+			return cancel(tx, t)
+		}
+	}
+
+	return nil
+}
+
+// cancel moves t to the cancelled state and refunds it.
+func cancel(tx storage.Tx, t *types.Tournament) error {
+	if err := lifecycle.Apply(t, lifecycle.Cancel); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t.FinishedAt = &now
+
+	return refund(tx, t)
+}
+
+// joinable tells why a player can not join t at now, nil when they can. A
+// re-entry does not take another place in the field.
+func joinable(t *types.Tournament, playerId string, now time.Time) error {
+	if err := registrationOpen(t, now); err != nil {
+		return err
+	}
+
+	if t.MaxEntrants > 0 && len(t.PlayerEntries(playerId)) == 0 && t.Entrants() >= t.MaxEntrants {
+		return errors.New("tournament is full")
+	}
+
+	return nil
+}
+
+// registrationOpen tells why t does not take buy-ins at now, nil when it
+// does: either its registration or its late registration is open.
+func registrationOpen(t *types.Tournament, now time.Time) error {
+	switch {
+	case lifecycle.Allows(t, lifecycle.Join):
+		if t.RegistrationOpensAt != nil && now.Before(*t.RegistrationOpensAt) {
+			return errors.New("tournament registration opens at " + t.RegistrationOpensAt.Format(time.RFC3339))
+		}
+		if t.RegistrationClosesAt != nil && !now.Before(*t.RegistrationClosesAt) {
+			return errors.New("tournament registration closed at " + t.RegistrationClosesAt.Format(time.RFC3339))
+		}
+	case lifecycle.Allows(t, lifecycle.LateJoin) && t.LateRegistration > 0 && t.StartedAt != nil:
+		if ends := t.StartedAt.Add(t.LateRegistration); !now.Before(ends) {
+			return errors.New("tournament late registration ended at " + ends.Format(time.RFC3339))
+		}
+	default:
+		return errors.New("tournament registration is not open")
+	}
+
+	return nil
+}
+
+// TournamentStatusHandler reports the state of a tournament and the
+// operations allowed in it.
+func (h *Handlers) TournamentStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t, err := h.store.GetTournament(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	j, err := json.Marshal(struct {
+		Id         string                `json:"id"`
+		State      string                `json:"state"`
+		Entries    int                   `json:"entries"`
+		Operations []lifecycle.Operation `json:"operations"`
+	}{t.Id, t.State, len(t.Entries), lifecycle.Allowed(t)})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// CancelTournamentHandler cancels a tournament that has not resulted yet.
+// Every entrant gets their own contribution back and every backer the amount
+// they backed with, so the tournament pool ends up empty.
+func (h *Handlers) CancelTournamentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	tournamentId, err := utils.GetStringURLParam(params, "tournamentId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid tournamentId given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	err = cancel(tx, t)
+	if err == lifecycle.ErrNotAllowed {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament can not be cancelled in state " + t.State)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.UpdateTournament(t); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
+}
+
+// refund pays the stakes of every entry of t back from the tournament pool
+// and the rake back from the house, into escrow for frozen players. All the players involved are locked at
+// once so that a player who both entered and backed is refunded from a
+// single row.
+func refund(tx storage.Tx, t *types.Tournament) error {
+	refunds := make(map[string]uint64)
+	fees := make(map[string]uint64)
+	for _, e := range t.Entries {
+		refunds[e.PlayerId] += e.Contribution
+		fees[e.PlayerId] += e.Rake
+		for _, b := range e.Backings {
+			refunds[b.BackerId] += b.Amount + b.Premium
+		}
+	}
+
+	ids := make([]string, 0, len(refunds))
+	for id := range refunds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	players, err := tx.GetPlayersForUpdate(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range players {
+		err = ledger.Transfer(tx, ledger.Tournament(t.Id), payee(p), refunds[p.Id], ledger.ReasonRefund, t.Id)
+		if err != nil {
+			return err
+		}
+
+		err = ledger.Transfer(tx, ledger.House, payee(p), fees[p.Id], ledger.ReasonRefund, t.Id)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.UpdatePlayer(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tournament is a tournament as the API returns it, with every key in camel
+// case: types.Tournament keeps Id and Deposit untagged the way it is stored.
+type tournament struct {
+	Id                   string        `json:"id"`
+	Deposit              uint64        `json:"deposit"`
+	Rounding             string        `json:"rounding"`
+	State                string        `json:"state"`
+	Payout               string        `json:"payout"`
+	Rake                 uint64        `json:"rake"`
+	RakeBasisPoints      uint64        `json:"rakeBasisPoints"`
+	Reentries            int           `json:"reentries"`
+	Rebuys               int           `json:"rebuys"`
+	Guarantee            uint64        `json:"guarantee"`
+	Overlay              uint64        `json:"overlay"`
+	MaxEntrants          int           `json:"maxEntrants"`
+	MinEntrants          int           `json:"minEntrants"`
+	RegistrationOpensAt  *time.Time    `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time    `json:"registrationClosesAt,omitempty"`
+	LateRegistration     time.Duration `json:"lateRegistration"`
+	CreatedAt            time.Time     `json:"createdAt"`
+	StartedAt            *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt           *time.Time    `json:"finishedAt,omitempty"`
+}
+
+func newTournament(t *types.Tournament) tournament {
+	return tournament{
+		Id:                   t.Id,
+		Deposit:              t.Deposit,
+		Rounding:             t.Rounding,
+		State:                t.State,
+		Payout:               t.Payout,
+		Rake:                 t.Rake,
+		RakeBasisPoints:      t.RakeBasisPoints,
+		Reentries:            t.Reentries,
+		Rebuys:               t.Rebuys,
+		Guarantee:            t.Guarantee,
+		Overlay:              t.Overlay,
+		MaxEntrants:          t.MaxEntrants,
+		MinEntrants:          t.MinEntrants,
+		RegistrationOpensAt:  t.RegistrationOpensAt,
+		RegistrationClosesAt: t.RegistrationClosesAt,
+		LateRegistration:     t.LateRegistration,
+		CreatedAt:            t.CreatedAt,
+		StartedAt:            t.StartedAt,
+		FinishedAt:           t.FinishedAt,
+	}
+}
+
+// TournamentHandler returns a tournament with its entries, their backings,
+// the prizes won and the payouts to the backers.
+func (h *Handlers) TournamentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t, err := h.store.GetTournament(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	type backing struct {
+		BackerId string `json:"backerId"`
+		Amount   uint64 `json:"amount"`
+		Premium  uint64 `json:"premium"`
+		Payout   uint64 `json:"payout"`
+	}
+
+	type entry struct {
+		PlayerId     string    `json:"playerId"`
+		Entry        int       `json:"entry"`
+		Rebuys       int       `json:"rebuys"`
+		Contribution uint64    `json:"contribution"`
+		Prize        uint64    `json:"prize"`
+		Rank         int       `json:"rank"`
+		Backings     []backing `json:"backings"`
+	}
+
+	resp := struct {
+		tournament
+		Entries []entry `json:"entries"`
+	}{tournament: newTournament(t), Entries: []entry{}}
+
+	for _, e := range t.Entries {
+		v := entry{
+			PlayerId:     e.PlayerId,
+			Entry:        e.Number,
+			Rebuys:       e.Rebuys,
+			Contribution: e.Contribution,
+			Prize:        e.Prize,
+			Rank:         e.Rank,
+			Backings:     []backing{},
+		}
+		for _, b := range e.Backings {
+			v.Backings = append(v.Backings, backing{b.BackerId, b.Amount, b.Premium, b.Payout})
+		}
+
+		resp.Entries = append(resp.Entries, v)
+	}
+
+	sort.Slice(resp.Entries, func(i, j int) bool {
+		a, b := resp.Entries[i], resp.Entries[j]
+		if a.PlayerId != b.PlayerId {
+			return a.PlayerId < b.PlayerId
+		}
+		return a.Entry < b.Entry
+	})
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// TournamentsHandler lists tournaments newest first. They can be filtered by
+// state and by the time they were announced, cursor and limit page through
+// them.
+func (h *Handlers) TournamentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	f := types.TournamentFilter{
+		States: params["state"],
+		Limit:  50,
+	}
+
+	var err error
+	if params.Get("from") != "" {
+		if f.From, err = utils.GetTimeURLParam(params, "from"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid from given")
+			return
+		}
+	}
+
+	if params.Get("to") != "" {
+		if f.To, err = utils.GetTimeURLParam(params, "to"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid to given")
+			return
+		}
+	}
+
+	if params.Get("cursor") != "" {
+		if f.After, err = utils.GetCursorURLParam(params, "cursor"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid cursor given")
+			return
+		}
+	}
+
+	if params.Get("limit") != "" {
+		limit, err := utils.GetUintURLParam(params, "limit")
+		if err != nil || limit == 0 || limit > 500 {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid limit given")
+			return
+		}
+		f.Limit = int(limit)
+	}
+
+	// one more tournament tells whether there is a next page
+	limit := f.Limit
+	f.Limit++
+
+	tt, err := h.store.ListTournaments(f)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	resp := struct {
+		Tournaments []tournament `json:"tournaments"`
+		NextCursor  string       `json:"nextCursor,omitempty"`
+	}{Tournaments: []tournament{}}
+
+	if len(tt) > limit {
+		tt = tt[:limit]
+		resp.NextCursor = utils.NewCursor(tt[limit-1].Id)
+	}
+	for _, t := range tt {
+		resp.Tournaments = append(resp.Tournaments, newTournament(t))
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// ApproveOverlayHandler sets how many points the house may add to the prize
+// pool of a tournament when its prizes exceed what the entries paid in.
+func (h *Handlers) ApproveOverlayHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	tournamentId, err := utils.GetStringURLParam(params, "tournamentId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid tournamentId given")
+		return
+	}
+
+	points, err := utils.GetUintURLParam(params, "points")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid points given")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if t.FinishedAt != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("tournament is already " + t.State)
+		return
+	}
+
+	t.Overlay = points
+
+	if err = tx.UpdateTournament(t); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
+}
+
+// PrizePoolHandler reports the prize pool of a tournament: what the entries
+// paid in, the rake taken from them, the guarantee and the overlay approved
+// for it and the prizes paid out of it.
+func (h *Handlers) PrizePoolHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	t, err := h.store.GetTournament(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	resp := struct {
+		Id        string `json:"id"`
+		Collected uint64 `json:"collected"`
+		// Rake is what the entries paid the house on top of the pool.
+		Rake      uint64 `json:"rake"`
+		Guarantee uint64 `json:"guarantee"`
+		Overlay   uint64 `json:"overlay"`
+		// Total is the most the tournament can pay in prizes.
+		Total  uint64 `json:"total"`
+		Prizes uint64 `json:"prizes"`
+		// OverlayUsed is what the house paid into the pool for prizes beyond
+		// what the entries paid in.
+		OverlayUsed uint64 `json:"overlayUsed"`
+	}{
+		Id:        t.Id,
+		Collected: t.Collected(),
+		Rake:      t.Raked(),
+		Guarantee: t.Guarantee,
+		Overlay:   t.Overlay,
+		Prizes:    t.Prizes(),
+	}
+
+	resp.Total = t.Pool() + resp.Overlay
+
+	// the house pays in when the tournament is resulted
+	if t.State == lifecycle.Resulted {
+		resp.OverlayUsed = t.OverlayUsed(resp.Prizes)
+	}
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// RebuyHandler buys an entry in again for the deposit of the tournament. The
+// player pays the rebuy alone, so backers keep their stake in the entry but
+// their share of its prize shrinks.
+func (h *Handlers) RebuyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	tournamentId, err := utils.GetStringURLParam(params, "tournamentId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid tournamentId given")
+		return
+	}
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	var number uint64
+	if params.Get("entry") != "" {
+		if number, err = utils.GetUintURLParam(params, "entry"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid entry given")
+			return
+		}
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	t, err := tx.GetTournamentForUpdate(tournamentId)
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such tournament")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = registrationOpen(t, time.Now()); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		log.Println(err.Error())
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	e, err := entryOf(t, playerId, int(number))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println(err.Error())
+		return
+	}
+
+	if e.Rebuys >= t.Rebuys {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("entry has no rebuys left")
+		return
+	}
+
+	p, err := tx.GetPlayersForUpdate([]string{playerId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(p) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	if err = restricted(p[0], time.Now()); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		log.Println(err.Error())
+		return
+	}
+
+	fee := t.Fee()
+	for _, transfer := range []struct {
+		credit ledger.Account
+		points uint64
+		reason string
+	}{
+		{ledger.Tournament(t.Id), t.Deposit, ledger.ReasonRebuy},
+		{ledger.House, fee, ledger.ReasonRake},
+	} {
+		err = ledger.Transfer(tx, ledger.Player(p[0]), transfer.credit, transfer.points, transfer.reason, t.Id)
+		if err == ledger.ErrInsufficientPoints {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("player has insufficient points")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	e.Contribution += t.Deposit
+	e.Rake += fee
+	e.Rebuys++
+
+	if err = tx.UpdateTournamentEntry(e); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.UpdatePlayer(p[0]); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCancelRefunds(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&backerId=b&backerAmount=40", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusOK)
+	s.balances(map[string][2]float64{"a": {940, 940}, "b": {960, 960}, "c": {900, 900}})
+
+	s.get("/cancelTournament?tournamentId=t1", http.StatusOK)
+	s.get("/cancelTournament?tournamentId=t1", http.StatusConflict)
+	s.get("/joinTournament?tournamentId=t1&playerId=c", http.StatusConflict)
+
+	// players and backers each get back what they paid in
+	s.balances(map[string][2]float64{"a": {1000, 1000}, "b": {1000, 1000}, "c": {1000, 1000}})
+}
+
+// Resulted tournaments are kept, and read back with camel case keys only.
+func TestTournamentHistory(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/announceTournament?tournamentId=t2&deposit=50", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":100}]}`, http.StatusOK)
+
+	tt := s.get("/tournaments/t1", http.StatusOK)
+	if tt["id"] != "t1" || tt["deposit"] != 100.0 || tt["state"] != "resulted" {
+		t.Errorf("tournament is %v", tt)
+	}
+	for _, key := range []string{"Id", "Deposit"} {
+		if _, ok := tt[key]; ok {
+			t.Errorf("tournament has key %q", key)
+		}
+	}
+
+	list := s.get("/tournaments?state=resulted", http.StatusOK)["tournaments"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["id"] != "t1" {
+		t.Errorf("resulted tournaments are %v", list)
+	}
+}
+
+func TestGuaranteedPool(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100&guarantee=1000", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=b", http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+
+	// the guarantee is paid out in full, not only what the house is asked for
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":500}]}`, http.StatusBadRequest)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":1001}]}`, http.StatusBadRequest)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":700},{"playerId":"b","prize":300}]}`, http.StatusOK)
+
+	if pool := s.get("/tournaments/t1/pool", http.StatusOK); pool["overlayUsed"] != 800.0 {
+		t.Errorf("overlay used is %v, want 800", pool["overlayUsed"])
+	}
+	s.balances(map[string][2]float64{"a": {1600, 1600}, "b": {1200, 1200}})
+}
+
+func TestResultPaysOutPool(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100", http.StatusOK)
+	s.get("/approveOverlay?tournamentId=t1&points=50", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=b", http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+	s.get("/startTournament?tournamentId=t1", http.StatusOK)
+
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":199}]}`, http.StatusBadRequest)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":251}]}`, http.StatusBadRequest)
+	s.post("/resultTournament", `{"tournamentId":"t1","winners":[{"playerId":"a","prize":250}]}`, http.StatusOK)
+	s.balances(map[string][2]float64{"a": {1150, 1150}, "b": {900, 900}})
+}
+
+func TestEntrantLimits(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a", "b", "c")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100&minEntrants=3&maxEntrants=2", http.StatusBadRequest)
+
+	// a re-entry does not take another place in the field
+	s.get("/announceTournament?tournamentId=t1&deposit=100&maxEntrants=2&reentries=1", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&reentry=true", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=b", http.StatusOK)
+	if reason := string(s.send(http.MethodGet, "/joinTournament?tournamentId=t1&playerId=c", nil, http.StatusConflict)); reason != "tournament is full" {
+		t.Errorf("join is refused with %q", reason)
+	}
+	s.balances(map[string][2]float64{"a": {800, 800}, "b": {900, 900}, "c": {1000, 1000}})
+}
+
+func TestRegistrationDeadline(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a")
+
+	closed := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	s.get("/announceTournament?tournamentId=t1&deposit=100&registrationClosesAt="+closed, http.StatusOK)
+	reason := string(s.send(http.MethodGet, "/joinTournament?tournamentId=t1&playerId=a", nil, http.StatusConflict))
+	if !strings.HasPrefix(reason, "tournament registration closed at ") {
+		t.Errorf("join is refused with %q", reason)
+	}
+}
+
+func TestMinimumFieldCancels(t *testing.T) {
+	s := newTestServer(t, Config{})
+	s.register("1000", "a")
+
+	s.get("/announceTournament?tournamentId=t1&deposit=100&minEntrants=2&reentries=1", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+	s.get("/joinTournament?tournamentId=t1&playerId=a&reentry=true", http.StatusOK)
+	s.get("/closeRegistration?tournamentId=t1", http.StatusOK)
+
+	// two entries of one player are not a field of two
+	s.get("/startTournament?tournamentId=t1", http.StatusConflict)
+	if st := s.get("/tournaments/t1/status", http.StatusOK); st["state"] != "cancelled" {
+		t.Errorf("tournament is %v, want cancelled", st["state"])
+	}
+	s.balances(map[string][2]float64{"a": {1000, 1000}})
+}
+
+func TestRake(t *testing.T) {
+	tests := []struct {
+		query string
+		paid  float64
+	}{
+		{"rake=10", 110},
+		{"rakePercent=7.5", 107},
+		{"rake=10&rakePercent=5", 0},
+	}
+
+	for _, tt := range tests {
+		s := newTestServer(t, Config{})
+		s.register("1000", "a")
+
+		if tt.paid == 0 {
+			s.get("/announceTournament?tournamentId=t1&deposit=100&"+tt.query, http.StatusBadRequest)
+			continue
+		}
+
+		s.get("/announceTournament?tournamentId=t1&deposit=100&"+tt.query, http.StatusOK)
+		s.get("/joinTournament?tournamentId=t1&playerId=a", http.StatusOK)
+		s.balances(map[string][2]float64{"a": {1000 - tt.paid, 1000 - tt.paid}})
+
+		// the rake is only kept by the house for tournaments that are played
+		s.get("/cancelTournament?tournamentId=t1", http.StatusOK)
+		s.balances(map[string][2]float64{"a": {1000, 1000}})
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/xfreshx/lifland/ledger"
+	"github.com/xfreshx/lifland/storage"
+	"github.com/xfreshx/lifland/types"
+	"github.com/xfreshx/lifland/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SendTransferHandler moves points from playerId to recipientId. When
+// transfers need accepting the points are held until the recipient accepts
+// or declines them, otherwise the recipient is credited right away.
+func (h *Handlers) SendTransferHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	playerId, err := utils.GetStringURLParam(params, "playerId")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid playerId given")
+		return
+	}
+
+	recipientId, err := utils.GetStringURLParam(params, "recipientId")
+	if err != nil || recipientId == playerId {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid recipientId given")
+		return
+	}
+
+	points, err := utils.GetUintURLParam(params, "points")
+	if err != nil || points == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("invalid points given")
+		return
+	}
+
+	if h.cfg.TransferMax > 0 && points > h.cfg.TransferMax {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("transfers are limited to " + strconv.FormatUint(h.cfg.TransferMax, 10) + " points")
+		return
+	}
+
+	tx, err := h.store.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	// both players are locked at once, in id order
+	pp, err := tx.GetPlayersForUpdate([]string{playerId, recipientId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if len(pp) != 2 {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such player")
+		return
+	}
+
+	sender, recipient := pp[0], pp[1]
+	if sender.Id != playerId {
+		sender, recipient = recipient, sender
+	}
+
+	now := time.Now()
+	for _, p := range pp {
+		if err = restricted(p, now); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	// the lock on the sender keeps its concurrent transfers from both passing
+	if h.cfg.TransferDailyMax > 0 {
+		sent, err := tx.TransferredSince(sender.Id, now.Add(-24*time.Hour))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if sent+points > h.cfg.TransferDailyMax {
+			w.WriteHeader(http.StatusConflict)
+			log.Println("player would exceed the daily transfer limit")
+			return
+		}
+	}
+
+	tr := &types.Transfer{
+		Id:          utils.NewId(),
+		PlayerId:    sender.Id,
+		RecipientId: recipient.Id,
+		Amount:      points,
+		Status:      types.TransferCompleted,
+		SettledAt:   &now,
+	}
+
+	var credit ledger.Account = ledger.Player(recipient)
+	if h.cfg.TransferAccept {
+		credit = ledger.Pending(tr.Id)
+		tr.Status, tr.SettledAt = types.TransferPending, nil
+	}
+
+	err = ledger.Transfer(tx, ledger.Player(sender), credit, points, ledger.ReasonTransfer, tr.Id)
+	if err == ledger.ErrInsufficientPoints {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("player has insufficient points")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	if err = tx.AddTransfer(tr); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	for _, p := range pp {
+		if err = tx.UpdatePlayer(p); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeTransfer(w, tr)
+}
+
+// SettleTransferHandler returns a handler settling the pending transfer given
+// by transferId with status. The recipient given by playerId accepts or
+// declines a transfer, its sender cancels it. Points not accepted go back to
+// the sender.
+func (h *Handlers) SettleTransferHandler(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+
+		transferId, err := utils.GetStringURLParam(params, "transferId")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid transferId given")
+			return
+		}
+
+		playerId, err := utils.GetStringURLParam(params, "playerId")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("invalid playerId given")
+			return
+		}
+
+		tx, err := h.store.Begin()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+		defer tx.Rollback()
+
+		tr, err := tx.GetTransferForUpdate(transferId)
+		if err == storage.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such transfer")
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		party := tr.RecipientId
+		if status == types.TransferCancelled {
+			party = tr.PlayerId
+		}
+		if playerId != party {
+			w.WriteHeader(http.StatusForbidden)
+			log.Println("player is not a party to settle the transfer")
+			return
+		}
+
+		if tr.Status != types.TransferPending {
+			w.WriteHeader(http.StatusConflict)
+			log.Println("transfer is already " + tr.Status)
+			return
+		}
+
+		payeeId := tr.PlayerId
+		if status == types.TransferCompleted {
+			payeeId = tr.RecipientId
+		}
+
+		pp, err := tx.GetPlayersForUpdate([]string{payeeId})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if len(pp) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			log.Println("no such player")
+			return
+		}
+
+		var credit ledger.Account
+		reason := ledger.ReasonRefund
+		if status == types.TransferCompleted {
+			if err = restricted(pp[0], time.Now()); err != nil {
+				w.WriteHeader(http.StatusForbidden)
+				log.Println(err.Error())
+				return
+			}
+
+			credit, reason = ledger.Player(pp[0]), ledger.ReasonTransfer
+		} else {
+			// the sender gets its points back even if it was frozen meanwhile
+			credit = payee(pp[0])
+		}
+
+		err = ledger.Transfer(tx, ledger.Pending(tr.Id), credit, tr.Amount, reason, tr.Id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.UpdatePlayer(pp[0]); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		now := time.Now()
+		tr.Status, tr.SettledAt = status, &now
+
+		if err = tx.UpdateTransfer(tr); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println(err.Error())
+			return
+		}
+
+		writeTransfer(w, tr)
+	}
+}
+
+// TransferHandler returns a transfer with its status.
+func (h *Handlers) TransferHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	tr, err := h.store.GetTransfer(mux.Vars(r)["id"])
+	if err == storage.ErrNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("no such transfer")
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	writeTransfer(w, tr)
+}
+
+func writeTransfer(w http.ResponseWriter, tr *types.Transfer) {
+	j, err := json.Marshal(tr)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println(err.Error())
+		return
+	}
+
+	_, err = w.Write(j)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
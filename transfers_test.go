@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTransferLimits(t *testing.T) {
+	s := newTestServer(t, Config{TransferMax: 500, TransferDailyMax: 700})
+	s.register("1000", "a", "b")
+	s.post("/players", `{"id":"c","name":"c"}`, http.StatusCreated)
+
+	steps := []struct {
+		query  string
+		status int
+	}{
+		{"playerId=a&recipientId=b&points=501", http.StatusBadRequest},
+		{"playerId=a&recipientId=a&points=100", http.StatusBadRequest},
+		{"playerId=a&recipientId=x&points=100", http.StatusNotFound},
+		{"playerId=c&recipientId=a&points=100", http.StatusBadRequest},
+		{"playerId=a&recipientId=b&points=400", http.StatusOK},
+		// the daily limit counts what was sent today
+		{"playerId=a&recipientId=b&points=301", http.StatusConflict},
+		{"playerId=a&recipientId=c&points=300", http.StatusOK},
+		{"playerId=a&recipientId=b&points=1", http.StatusConflict},
+		{"playerId=b&recipientId=a&points=500", http.StatusOK},
+	}
+
+	for _, step := range steps {
+		s.get("/transfer?"+step.query, step.status)
+	}
+	s.balances(map[string][2]float64{"a": {800, 800}, "b": {900, 900}, "c": {300, 300}})
+}
+
+func TestTransfersToAccept(t *testing.T) {
+	s := newTestServer(t, Config{TransferAccept: true})
+	s.register("1000", "a", "b")
+
+	tr := s.get("/transfer?playerId=a&recipientId=b&points=100", http.StatusOK)
+	if tr["status"] != "pending" {
+		t.Errorf("transfer is %v, want pending", tr["status"])
+	}
+	s.balances(map[string][2]float64{"a": {900, 900}, "b": {1000, 1000}})
+
+	s.get("/acceptTransfer?transferId="+tr["id"].(string)+"&playerId=a", http.StatusForbidden)
+	s.get("/acceptTransfer?transferId="+tr["id"].(string)+"&playerId=b", http.StatusOK)
+	s.get("/declineTransfer?transferId="+tr["id"].(string)+"&playerId=b", http.StatusConflict)
+	s.balances(map[string][2]float64{"a": {900, 900}, "b": {1100, 1100}})
+
+	tr = s.get("/transfer?playerId=a&recipientId=b&points=200", http.StatusOK)
+	s.get("/declineTransfer?transferId="+tr["id"].(string)+"&playerId=b", http.StatusOK)
+	tr = s.get("/transfer?playerId=a&recipientId=b&points=300", http.StatusOK)
+	s.get("/cancelTransfer?transferId="+tr["id"].(string)+"&playerId=a", http.StatusOK)
+	s.balances(map[string][2]float64{"a": {900, 900}, "b": {1100, 1100}})
+}
@@ -1,52 +1,405 @@
 package types
 
 import (
-	"encoding/json"
-	"log"
+	"math/bits"
+	"sort"
+	"strconv"
+	"time"
 )
 
 type Player struct {
-	Id      string                 `json:"id"`
-	Points  uint64                 `json:"balance"`
-	Backers map[string]interface{} `json:"-"`
+	Id     string `json:"id"`
+	Points uint64 `json:"balance"`
+	// Name is the display name of the player.
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+	Status   string            `json:"status"`
+	// StatusReason and StatusBy record why and by whom the status was last
+	// changed. StatusUntil is when a suspension expires.
+	StatusReason string     `json:"statusReason,omitempty"`
+	StatusBy     string     `json:"statusBy,omitempty"`
+	StatusUntil  *time.Time `json:"statusUntil,omitempty"`
+	// CreatedAt is when the player registered, UpdatedAt when the profile
+	// last changed.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Held is the part of Points reserved by active holds.
+	Held uint64 `json:"-"`
 }
 
-func (p *Player) GetBackersJson() string {
-	b, err := json.Marshal(p.Backers)
-	if err != nil {
-		log.Println(err)
-		return ""
+// Available is what the player may spend: its points not held.
+func (p *Player) Available() uint64 {
+	if p.Held > p.Points {
+		return 0
 	}
 
-	return string(b)
+	return p.Points - p.Held
 }
 
-func (p *Player) SetBackers(j string) {
-	err := json.Unmarshal([]byte(j), &p.Backers)
-	if err != nil {
-		log.Println(err)
+// Statuses of a player account. A suspended player may not move points until
+// the suspension expires, a frozen one until it is reviewed and what it wins
+// meanwhile is held in escrow.
+const (
+	PlayerActive    = "active"
+	PlayerSuspended = "suspended"
+	PlayerFrozen    = "frozen"
+	PlayerClosed    = "closed"
+)
+
+// StatusAt is the status of the player at now, active once a suspension has
+// expired.
+func (p *Player) StatusAt(now time.Time) string {
+	if p.Status == PlayerSuspended && p.StatusUntil != nil && !now.Before(*p.StatusUntil) {
+		return PlayerActive
 	}
+
+	return p.Status
 }
 
 type Tournament struct {
-	Id      string
-	Players map[string]interface{}
+	Id string
+	// Entries are keyed by EntryKey.
+	Entries map[string]*Entry `json:"-"`
 	Deposit uint64
+	// Rounding is the policy used to split the deposit and prizes of the tournament.
+	Rounding string `json:"rounding"`
+	State    string `json:"state"`
+	// Payout is the structure prizes are computed with from a finishing
+	// order, none when empty.
+	Payout string `json:"payout"`
+	// Rake is the fee in points the house takes from every entry on top of
+	// the deposit. RakeBasisPoints takes it as hundredths of a percent of
+	// the deposit instead.
+	Rake            uint64 `json:"rake"`
+	RakeBasisPoints uint64 `json:"rakeBasisPoints"`
+	// Reentries is how many times a player may enter again, Rebuys how many
+	// times an entry may buy in again.
+	Reentries int `json:"reentries"`
+	Rebuys    int `json:"rebuys"`
+	// Guarantee is the smallest prize pool the house promised, it pays what
+	// the entries fall short of it.
+	Guarantee uint64 `json:"guarantee"`
+	// Overlay is the most the house agreed to add when the prizes exceed
+	// what the entries paid in.
+	Overlay uint64 `json:"overlay"`
+	// MaxEntrants and MinEntrants bound the number of players entered, not
+	// their entries, 0 leaves that side open. Nothing starts a tournament on
+	// its own: one short of MinEntrants is cancelled when /startTournament is
+	// called, or its result, not when its registration closes.
+	MaxEntrants int `json:"maxEntrants"`
+	MinEntrants int `json:"minEntrants"`
+	// RegistrationOpensAt and RegistrationClosesAt limit when players may
+	// join, a nil time leaves that side open.
+	RegistrationOpensAt  *time.Time `json:"registrationOpensAt,omitempty"`
+	RegistrationClosesAt *time.Time `json:"registrationClosesAt,omitempty"`
+	// LateRegistration lets players join for this long after the start.
+	LateRegistration time.Duration `json:"lateRegistration"`
+	// CreatedAt is when the tournament was first announced.
+	CreatedAt time.Time `json:"createdAt"`
+	// StartedAt is when the tournament went in progress.
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// FinishedAt is when the tournament was resulted or cancelled.
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// TournamentFilter selects tournaments, newest first.
+type TournamentFilter struct {
+	// States limits tournaments to the given states, all of them when empty.
+	States []string
+	// From and To bound CreatedAt, a zero time leaves that side open.
+	From time.Time
+	To   time.Time
+	// After is a cursor: only tournaments listed after the one with this id
+	// are returned.
+	After string
+	Limit int
+}
+
+// Collected is what the entries paid into the prize pool.
+func (t *Tournament) Collected() uint64 {
+	var collected uint64
+	for _, e := range t.Entries {
+		collected += e.Deposit()
+	}
+
+	return collected
+}
+
+// Pool is the prize pool: what the entries paid in, at least the guarantee.
+func (t *Tournament) Pool() uint64 {
+	if collected := t.Collected(); collected > t.Guarantee {
+		return collected
+	}
+
+	return t.Guarantee
+}
+
+// OverlayUsed is what the house pays into the pool for prizes beyond what
+// the entries paid in, whether for the guarantee or the overlay.
+func (t *Tournament) OverlayUsed(prizes uint64) uint64 {
+	if collected := t.Collected(); prizes > collected {
+		return prizes - collected
+	}
+
+	return 0
 }
 
-func (t *Tournament) GetPlayersJson() string {
-	b, err := json.Marshal(t.Players)
-	if err != nil {
-		log.Println(err)
-		return ""
+// Fee is the rake of an entry. A percentage is rounded down.
+func (t *Tournament) Fee() uint64 {
+	if t.RakeBasisPoints > 0 {
+		hi, lo := bits.Mul64(t.Deposit, t.RakeBasisPoints)
+		fee, _ := bits.Div64(hi, lo, 10000)
+		return fee
 	}
 
-	return string(b)
+	return t.Rake
 }
 
-func (t *Tournament) SetPlayers(j string) {
-	err := json.Unmarshal([]byte(j), &t.Players)
-	if err != nil {
-		log.Println(err)
+// Raked is what the house took from the entries.
+func (t *Tournament) Raked() uint64 {
+	var raked uint64
+	for _, e := range t.Entries {
+		raked += e.Rake
 	}
+
+	return raked
+}
+
+// Entrants counts the players entered, however many entries each has.
+func (t *Tournament) Entrants() int {
+	players := make(map[string]bool)
+	for _, e := range t.Entries {
+		players[e.PlayerId] = true
+	}
+
+	return len(players)
+}
+
+// PlayerEntries returns the entries of a player by number.
+func (t *Tournament) PlayerEntries(playerId string) []*Entry {
+	var ee []*Entry
+	for _, e := range t.Entries {
+		if e.PlayerId == playerId {
+			ee = append(ee, e)
+		}
+	}
+
+	sort.Slice(ee, func(i, j int) bool { return ee[i].Number < ee[j].Number })
+
+	return ee
+}
+
+// Prizes is what the entries won.
+func (t *Tournament) Prizes() uint64 {
+	var prizes uint64
+	for _, e := range t.Entries {
+		prizes += e.Prize
+	}
+
+	return prizes
+}
+
+// Entry is a player entered in a tournament along with the backers of
+// this particular entry. A player entering again gets a new entry.
+type Entry struct {
+	TournamentId string `json:"tournamentId"`
+	PlayerId     string `json:"playerId"`
+	// Number counts the entries of the player from 1.
+	Number int `json:"entry"`
+	// Rebuys is how many times the player bought in again to this entry.
+	Rebuys int `json:"rebuys"`
+	// Contribution is the part of the deposits paid by the player.
+	Contribution uint64 `json:"contribution"`
+	// Rake is the fee the player paid the house for the entry.
+	Rake uint64 `json:"rake"`
+	// Prize is what the entry won, before the backers are paid.
+	Prize uint64 `json:"prize"`
+	// Rank is the place the entry finished at, 0 when not ranked. Tied
+	// entries share a rank.
+	Rank     int        `json:"rank"`
+	Backings []*Backing `json:"-"`
+}
+
+// EntryKey identifies an entry within its tournament.
+func EntryKey(playerId string, number int) string {
+	return playerId + "#" + strconv.Itoa(number)
+}
+
+func (e *Entry) Key() string {
+	return EntryKey(e.PlayerId, e.Number)
+}
+
+// Deposit is what the entry paid into the prize pool.
+func (e *Entry) Deposit() uint64 {
+	var deposit uint64
+	for _, s := range e.Stakes() {
+		deposit += s
+	}
+
+	return deposit
+}
+
+// Stakes returns the contribution of the player followed by the
+// contributions of the backers, in the order of Backings.
+func (e *Entry) Stakes() []uint64 {
+	stakes := []uint64{e.Contribution}
+	for _, b := range e.Backings {
+		stakes = append(stakes, b.Amount)
+	}
+
+	return stakes
+}
+
+// Backing is a contribution of a backer to the deposit of a tournament entry.
+type Backing struct {
+	TournamentId string `json:"tournamentId"`
+	PlayerId     string `json:"playerId"`
+	Entry        int    `json:"entry"`
+	BackerId     string `json:"backerId"`
+	Amount       uint64 `json:"amount"`
+	// Premium is the markup the backer paid on top of the stake, escrowed
+	// by the tournament until its result and refunded if it is cancelled.
+	Premium uint64 `json:"premium"`
+	// Payout is the share of the prize of the entry paid to the backer.
+	Payout uint64 `json:"payout"`
+}
+
+// Transfer moves points from a player to a recipient. A transfer waiting for
+// the recipient to accept it holds the points in between.
+type Transfer struct {
+	Id string `json:"id"`
+	// PlayerId is the player sending the points.
+	PlayerId    string    `json:"playerId"`
+	RecipientId string    `json:"recipientId"`
+	Amount      uint64    `json:"amount"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// SettledAt is when the transfer was completed, declined or cancelled.
+	SettledAt *time.Time `json:"settledAt,omitempty"`
+}
+
+// Statuses of a transfer.
+const (
+	TransferPending   = "pending"
+	TransferCompleted = "completed"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+)
+
+// Hold reserves points of a player until it expires, is captured to be spent
+// or is released. Captured is the part of the hold that was spent, the rest
+// went back to the player.
+type Hold struct {
+	Id        string    `json:"id"`
+	PlayerId  string    `json:"playerId"`
+	Amount    uint64    `json:"amount"`
+	Captured  uint64    `json:"captured"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	// SettledAt is when the hold was captured or released.
+	SettledAt *time.Time `json:"settledAt,omitempty"`
+}
+
+// Statuses of a hold. An active hold past its expiry is expired.
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// StatusAt is the status of the hold at now.
+func (h *Hold) StatusAt(now time.Time) string {
+	if h.Status == HoldActive && !now.Before(h.ExpiresAt) {
+		return HoldExpired
+	}
+
+	return h.Status
+}
+
+// Offer puts up a share of the entry of a player in a tournament for backers
+// to buy. Shares sell and the player joins with them until the deadline.
+type Offer struct {
+	Id           string `json:"id"`
+	TournamentId string `json:"tournamentId"`
+	PlayerId     string `json:"playerId"`
+	// Share is the part of the entry offered and Markup what backers pay on
+	// top of their stake, both in hundredths of a percent.
+	Share    uint64    `json:"shareBasisPoints"`
+	Markup   uint64    `json:"markupBasisPoints"`
+	Deadline time.Time `json:"deadline"`
+	Status   string    `json:"status"`
+	// Entry is the entry the offer was taken up with.
+	Entry     int         `json:"entry,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Purchases []*Purchase `json:"purchases"`
+}
+
+// Statuses of an offer. An open offer past its deadline is expired.
+const (
+	OfferOpen      = "open"
+	OfferTaken     = "taken"
+	OfferCancelled = "cancelled"
+	OfferExpired   = "expired"
+)
+
+// StatusAt is the status of the offer at now.
+func (o *Offer) StatusAt(now time.Time) string {
+	if o.Status == OfferOpen && !now.Before(o.Deadline) {
+		return OfferExpired
+	}
+
+	return o.Status
+}
+
+// Sold is the part of the entry the backers bought, in hundredths of a
+// percent.
+func (o *Offer) Sold() uint64 {
+	var sold uint64
+	for _, p := range o.Purchases {
+		sold += p.Share
+	}
+
+	return sold
+}
+
+// Purchase is a share of an offer bought by a backer. Amount is the stake it
+// becomes in the entry and Premium the markup paid to the player once the
+// tournament is resulted, both held from the backer until the player joins.
+type Purchase struct {
+	OfferId   string    `json:"offerId"`
+	BackerId  string    `json:"backerId"`
+	Share     uint64    `json:"shareBasisPoints"`
+	Amount    uint64    `json:"amount"`
+	Premium   uint64    `json:"premium"`
+	HoldId    string    `json:"holdId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LedgerEntry moves points from the Debit account to the Credit account.
+// Each entry is balanced by itself: what one account loses the other gains.
+type LedgerEntry struct {
+	Id        uint64 `json:"id"`
+	Debit     string `json:"debit"`
+	Credit    string `json:"credit"`
+	Amount    uint64 `json:"amount"`
+	Reason    string `json:"reason"`
+	Reference string `json:"reference"`
+	// Rounding is the policy the amount was computed with, if it is a share of a split.
+	Rounding  string    `json:"rounding,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LedgerFilter selects ledger entries of an account, newest first.
+type LedgerFilter struct {
+	Account string
+	// Reasons limits entries to the given reasons, all of them when empty.
+	Reasons []string
+	// From and To bound CreatedAt, a zero time leaves that side open.
+	From time.Time
+	To   time.Time
+	// Before is a cursor: only entries with a smaller id are returned.
+	Before uint64
+	Limit  int
 }
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 func GetStringURLParam(params url.Values, name string) (string, error) {
@@ -28,3 +32,54 @@ func GetUintURLParam(params url.Values, name string) (uint64, error) {
 
 	return ret, nil
 }
+
+// GetTimeURLParam parses an RFC 3339 timestamp param.
+func GetTimeURLParam(params url.Values, name string) (time.Time, error) {
+	_ret := params.Get(name)
+	if _ret == "" {
+		return time.Time{}, errors.New("no such param")
+	}
+
+	return time.Parse(time.RFC3339, _ret)
+}
+
+// NewCursor makes the opaque cursor of the page after the item with the
+// given id.
+func NewCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// GetCursorURLParam returns the id a cursor param was made from by NewCursor.
+func GetCursorURLParam(params url.Values, name string) (string, error) {
+	_ret := params.Get(name)
+	if _ret == "" {
+		return "", errors.New("no such param")
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(_ret)
+	if err != nil {
+		return "", err
+	}
+	if len(id) == 0 {
+		return "", errors.New("empty cursor")
+	}
+
+	return string(id), nil
+}
+
+// NewId returns a random 128-bit id in hex.
+func NewId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// GetRequestId returns the requestId param, or a new id when it is not given.
+func GetRequestId(params url.Values) string {
+	if id := params.Get("requestId"); id != "" {
+		return id
+	}
+
+	return NewId()
+}